@@ -0,0 +1,187 @@
+package sub
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinArgs returns a function that returns an error if it is given
+// fewer than n arguments. Like the other argument validators, it is
+// intended to be called at the start of a command's Run method:
+//
+//    if err := sub.MinArgs(1)(args); err != nil {
+//    	return err
+//    }
+func MinArgs(n int) func([]string) error {
+	return func(args []string) error {
+		if len(args) < n {
+			return fmt.Errorf("expected at least %v, got %v", arguments(n), len(args))
+		}
+		return nil
+	}
+}
+
+// MaxArgs returns a function that returns an error if it is given
+// more than n arguments.
+func MaxArgs(n int) func([]string) error {
+	return func(args []string) error {
+		if len(args) > n {
+			return fmt.Errorf("expected at most %v, got %v", arguments(n), len(args))
+		}
+		return nil
+	}
+}
+
+// ExactArgs returns a function that returns an error if it is not
+// given exactly n arguments.
+func ExactArgs(n int) func([]string) error {
+	return func(args []string) error {
+		if len(args) != n {
+			return fmt.Errorf("expected %v, got %v", arguments(n), len(args))
+		}
+		return nil
+	}
+}
+
+// RangeArgs returns a function that returns an error if it is given
+// fewer than min or more than max arguments.
+func RangeArgs(min, max int) func([]string) error {
+	return func(args []string) error {
+		if (len(args) < min) || (len(args) > max) {
+			return fmt.Errorf("expected between %v and %v arguments, got %v", min, max, len(args))
+		}
+		return nil
+	}
+}
+
+// NoArgs returns a function that returns an error if it is given any
+// arguments at all.
+func NoArgs() func([]string) error {
+	return func(args []string) error {
+		if len(args) != 0 {
+			return fmt.Errorf("expected no arguments, got %v", len(args))
+		}
+		return nil
+	}
+}
+
+// OnlyValidArgs returns a function that returns an error if it is
+// given any argument that isn't one of valid. Arguments are compared
+// case-sensitively.
+func OnlyValidArgs(valid ...string) func([]string) error {
+	return onlyValidArgs(valid, func(a, b string) bool { return a == b })
+}
+
+// OnlyValidArgsCI is like OnlyValidArgs, but compares arguments
+// case-insensitively.
+func OnlyValidArgsCI(valid ...string) func([]string) error {
+	return onlyValidArgs(valid, strings.EqualFold)
+}
+
+func onlyValidArgs(valid []string, eq func(a, b string) bool) func([]string) error {
+	return func(args []string) error {
+	outer:
+		for _, arg := range args {
+			for _, v := range valid {
+				if eq(arg, v) {
+					continue outer
+				}
+			}
+			return fmt.Errorf("invalid argument %q: expected one of %v", arg, valid)
+		}
+		return nil
+	}
+}
+
+// Chain returns a function that calls each of validators in order,
+// returning the first error encountered.
+func Chain(validators ...func([]string) error) func([]string) error {
+	return func(args []string) error {
+		for _, v := range validators {
+			err := v(args)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ArgsValidator is a Command that validates its own positional
+// arguments. If a command implements ArgsValidator, the Commander's
+// MinArgs and MaxArgs fields don't apply to it.
+type ArgsValidator interface {
+	Command
+
+	// ValidateArgs is passed the arguments that Run will be, after
+	// flags have been parsed. If it returns an error, Run isn't
+	// called. The validators in this package can be used to implement
+	// it:
+	//
+	//    func (cmd *getCmd) ValidateArgs(args []string) error {
+	//    	return sub.RangeArgs(1, 2)(args)
+	//    }
+	ValidateArgs(args []string) error
+}
+
+// argsValidatorOf returns cmd as an ArgsValidator, looking through the
+// package's wrappers, or nil if it isn't one.
+func argsValidatorOf(cmd Command) ArgsValidator {
+	if w, ok := cmd.(interface{ argsValidator() ArgsValidator }); ok {
+		return w.argsValidator()
+	}
+	if v, ok := cmd.(ArgsValidator); ok {
+		return v
+	}
+	return nil
+}
+
+// minArgs returns the MinArgs of c or, if it has none, of its nearest
+// parent that does.
+func (c *Commander) minArgs() int {
+	if (c.MinArgs == 0) && (c.parent != nil) {
+		return c.parent.minArgs()
+	}
+	return c.MinArgs
+}
+
+// maxArgs returns the MaxArgs of c or, if it has none, of its nearest
+// parent that does.
+func (c *Commander) maxArgs() int {
+	if (c.MaxArgs == 0) && (c.parent != nil) {
+		return c.parent.maxArgs()
+	}
+	return c.MaxArgs
+}
+
+// checkArgs returns an error if the positional arguments in args
+// aren't valid for cmd, either according to cmd itself, if it is an
+// ArgsValidator, or according to c's MinArgs and MaxArgs.
+func (c *Commander) checkArgs(cmd Command, args []string) error {
+	if v := argsValidatorOf(cmd); v != nil {
+		return v.ValidateArgs(args)
+	}
+
+	var validators []func([]string) error
+	if min := c.minArgs(); min != 0 {
+		validators = append(validators, MinArgs(min))
+	}
+	if max := c.maxArgs(); max != 0 {
+		validators = append(validators, MaxArgs(max))
+	}
+
+	err := Chain(validators...)(args)
+	if err != nil {
+		return fmt.Errorf("command %q: %v", cmd.Name(), err)
+	}
+	return nil
+}
+
+// arguments returns a string describing n arguments, such as "1
+// argument" or "2 arguments".
+func arguments(n int) string {
+	if n == 1 {
+		return "1 argument"
+	}
+	return fmt.Sprintf("%v arguments", n)
+}
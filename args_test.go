@@ -0,0 +1,147 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestArgValidators(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func([]string) error
+		args  []string
+		err   string
+	}{
+		{name: "MinArgs 0 Empty", check: sub.MinArgs(0)},
+		{name: "MinArgs 1 Empty", check: sub.MinArgs(1), err: "expected at least 1 argument, got 0"},
+		{name: "MinArgs 2 Under", check: sub.MinArgs(2), args: []string{"a"}, err: "expected at least 2 arguments, got 1"},
+		{name: "MinArgs 2 Exact", check: sub.MinArgs(2), args: []string{"a", "b"}},
+		{name: "MinArgs 2 Over", check: sub.MinArgs(2), args: []string{"a", "b", "c"}},
+
+		{name: "MaxArgs 0 Empty", check: sub.MaxArgs(0)},
+		{name: "MaxArgs 0 Over", check: sub.MaxArgs(0), args: []string{"a"}, err: "expected at most 0 arguments, got 1"},
+		{name: "MaxArgs 2 Under", check: sub.MaxArgs(2), args: []string{"a"}},
+		{name: "MaxArgs 2 Exact", check: sub.MaxArgs(2), args: []string{"a", "b"}},
+		{name: "MaxArgs 2 Over", check: sub.MaxArgs(2), args: []string{"a", "b", "c"}, err: "expected at most 2 arguments, got 3"},
+
+		{name: "ExactArgs 0 Empty", check: sub.ExactArgs(0)},
+		{name: "ExactArgs 0 Over", check: sub.ExactArgs(0), args: []string{"a"}, err: "expected 0 arguments, got 1"},
+		{name: "ExactArgs 1 Under", check: sub.ExactArgs(1), err: "expected 1 argument, got 0"},
+		{name: "ExactArgs 1 Exact", check: sub.ExactArgs(1), args: []string{"a"}},
+		{name: "ExactArgs 1 Over", check: sub.ExactArgs(1), args: []string{"a", "b"}, err: "expected 1 argument, got 2"},
+
+		{name: "RangeArgs 0 0 Empty", check: sub.RangeArgs(0, 0)},
+		{name: "RangeArgs 0 0 Over", check: sub.RangeArgs(0, 0), args: []string{"a"}, err: "expected between 0 and 0 arguments, got 1"},
+		{name: "RangeArgs 1 2 Under", check: sub.RangeArgs(1, 2), err: "expected between 1 and 2 arguments, got 0"},
+		{name: "RangeArgs 1 2 Min", check: sub.RangeArgs(1, 2), args: []string{"a"}},
+		{name: "RangeArgs 1 2 Max", check: sub.RangeArgs(1, 2), args: []string{"a", "b"}},
+		{name: "RangeArgs 1 2 Over", check: sub.RangeArgs(1, 2), args: []string{"a", "b", "c"}, err: "expected between 1 and 2 arguments, got 3"},
+
+		{name: "NoArgs Empty", check: sub.NoArgs()},
+		{name: "NoArgs Over", check: sub.NoArgs(), args: []string{"a"}, err: "expected no arguments, got 1"},
+
+		{name: "OnlyValidArgs Empty Set", check: sub.OnlyValidArgs(), args: []string{"a"}, err: `invalid argument "a": expected one of []`},
+		{name: "OnlyValidArgs No Args", check: sub.OnlyValidArgs("bar", "baz")},
+		{name: "OnlyValidArgs Valid", check: sub.OnlyValidArgs("bar", "baz"), args: []string{"baz", "bar", "baz"}},
+		{name: "OnlyValidArgs Invalid", check: sub.OnlyValidArgs("bar", "baz"), args: []string{"bar", "foo"}, err: `invalid argument "foo": expected one of [bar baz]`},
+		{name: "OnlyValidArgs Case", check: sub.OnlyValidArgs("bar", "baz"), args: []string{"Bar"}, err: `invalid argument "Bar": expected one of [bar baz]`},
+		{name: "OnlyValidArgsCI Case", check: sub.OnlyValidArgsCI("bar", "baz"), args: []string{"Bar", "BAZ"}},
+		{name: "OnlyValidArgsCI Invalid", check: sub.OnlyValidArgsCI("bar", "baz"), args: []string{"foo"}, err: `invalid argument "foo": expected one of [bar baz]`},
+
+		{name: "Chain Empty", check: sub.Chain()},
+		{name: "Chain Pass", check: sub.Chain(sub.MinArgs(1), sub.MaxArgs(2)), args: []string{"a"}},
+		{name: "Chain First", check: sub.Chain(sub.MinArgs(1), sub.MaxArgs(0)), err: "expected at least 1 argument, got 0"},
+		{name: "Chain Valid", check: sub.Chain(sub.MinArgs(1), sub.OnlyValidArgs("a")), err: "expected at least 1 argument, got 0"},
+		{name: "Chain Second", check: sub.Chain(sub.MinArgs(1), sub.MaxArgs(2)), args: []string{"a", "b", "c"}, err: "expected at most 2 arguments, got 3"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+			if err := test.check(test.args); err != nil {
+				got = err.Error()
+			}
+			if got != test.err {
+				t.Errorf("Expected:\t%q", test.err)
+				t.Errorf("Got:\t\t%q", got)
+			}
+		})
+	}
+}
+
+type validatedCmd struct {
+	sub.Command
+}
+
+func (cmd validatedCmd) ValidateArgs(args []string) error {
+	return sub.ExactArgs(4)(args)
+}
+
+func TestArgLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		ran  bool
+		err  string
+	}{
+		{name: "Within", args: []string{"subtest", "cmd", "a"}, ran: true},
+		{name: "Too Few", args: []string{"subtest", "cmd"}, err: `command "cmd": expected at least 1 argument, got 0`},
+		{name: "Too Many", args: []string{"subtest", "cmd", "-v", "a", "b", "c"}, err: `command "cmd": expected at most 2 arguments, got 3`},
+		{name: "Nested", args: []string{"subtest", "nested", "cmd", "a", "b", "c"}, err: `command "cmd": expected at most 2 arguments, got 3`},
+		{name: "Nested Override", args: []string{"subtest", "override", "cmd", "a", "b", "c"}, ran: true},
+		{name: "Validator", args: []string{"subtest", "custom", "a", "b", "c", "d"}, ran: true},
+		{name: "Validator Error", args: []string{"subtest", "custom", "a"}, err: "expected 4 arguments, got 1"},
+		{name: "Wrapped Validator", args: []string{"subtest", "hidden", "a", "b", "c", "d"}, ran: true},
+		{name: "Help", args: []string{"subtest", "help"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ran bool
+			cmd := func(name string) sub.Command {
+				return sub.Func(name, "", "", func(fset *flag.FlagSet) {
+					fset.Bool("v", false, "verbose")
+				}, func([]string) error {
+					ran = true
+					return nil
+				})
+			}
+
+			var nested, override sub.Commander
+			nested.Register(cmd("cmd"))
+			override.MaxArgs = 3
+			override.Register(cmd("cmd"))
+
+			c := &sub.Commander{MinArgs: 1, MaxArgs: 2, Silent: true, Output: new(bytes.Buffer)}
+			c.RegisterAll(
+				c.HelpCmd(),
+				cmd("cmd"),
+				validatedCmd{cmd("custom")},
+				sub.Hidden(validatedCmd{cmd("hidden")}),
+				nested.AsCommand("nested", ""),
+				override.AsCommand("override", ""),
+			)
+
+			var got string
+			if err := c.Run(test.args); err != nil {
+				got = err.Error()
+			}
+			if got != test.err {
+				t.Errorf("Expected:\t%q", test.err)
+				t.Errorf("Got:\t\t%q", got)
+			}
+			if ran != test.ran {
+				t.Errorf("Expected ran to be %v", test.ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package sub
+
+import "sync"
+
+// Batch runs each element of invocations, in order, as the arguments
+// to Run. Unlike a shell script run with set -e, it doesn't stop when
+// one of them fails. It returns the error from each invocation in the
+// same position as its arguments, with nil for those that succeeded.
+//
+//    errs := c.Batch([][]string{
+//    	{"prog", "create", "foo"},
+//    	{"prog", "delete", "bar"},
+//    })
+func (c *Commander) Batch(invocations [][]string) []error {
+	errs := make([]error, len(invocations))
+	for i, args := range invocations {
+		errs[i] = c.Run(args)
+	}
+	return errs
+}
+
+// BatchConcurrent is like Batch, but runs up to concurrency of the
+// invocations at the same time. If concurrency is less than one, all
+// of them are run at once. The invocations are started in order, but
+// may finish in any order, so commands that depend on each other
+// should be run with Batch instead. As Run is called concurrently, c
+// must not use output transformations added with UseOutput.
+func (c *Commander) BatchConcurrent(invocations [][]string, concurrency int) []error {
+	if (concurrency < 1) || (concurrency > len(invocations)) {
+		concurrency = len(invocations)
+	}
+
+	errs := make([]error, len(invocations))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, args := range invocations {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, args []string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = c.Run(args)
+		}(i, args)
+	}
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,112 @@
+package sub_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestBatch(t *testing.T) {
+	fail := errors.New("failed")
+
+	var ran []string
+	c := &sub.Commander{Silent: true}
+	c.RegisterAll(
+		sub.Func("create", "", "", nil, func(args []string) error {
+			ran = append(ran, "create "+args[0])
+			return nil
+		}),
+		sub.Func("fail", "", "", nil, func([]string) error {
+			ran = append(ran, "fail")
+			return fail
+		}),
+	)
+
+	errs := c.Batch([][]string{
+		{"subtest", "create", "foo"},
+		{"subtest", "fail"},
+		{"subtest", "missing"},
+		{"subtest", "create", "bar"},
+	})
+	if len(errs) != 4 {
+		t.Fatalf("Expected 4 errors, got %v", len(errs))
+	}
+	if (errs[0] != nil) || (errs[1] != fail) || (errs[3] != nil) {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+	var unknown *sub.UnknownCommandError
+	if !errors.As(errs[2], &unknown) {
+		t.Errorf("Expected:\t%v", &sub.UnknownCommandError{Name: "missing"})
+		t.Errorf("Got:\t\t%v", errs[2])
+	}
+
+	want := []string{"create foo", "fail", "create bar"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", ran)
+	}
+}
+
+func TestBatchConcurrent(t *testing.T) {
+	fail := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		concurrency int
+		max         int32
+	}{
+		{name: "Limited", concurrency: 2, max: 2},
+		{name: "Unlimited", concurrency: 0, max: 6},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var running, max int32
+			var mu sync.Mutex
+			c := &sub.Commander{Silent: true}
+			c.Register(sub.Func("work", "", "", nil, func(args []string) error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				mu.Lock()
+				if n > max {
+					max = n
+				}
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+				if args[0] == "fail" {
+					return fail
+				}
+				return nil
+			}))
+
+			invocations := make([][]string, 6)
+			for i := range invocations {
+				invocations[i] = []string{"subtest", "work", "ok"}
+			}
+			invocations[3][2] = "fail"
+
+			errs := c.BatchConcurrent(invocations, test.concurrency)
+			want := []error{nil, nil, nil, fail, nil, nil}
+			if !reflect.DeepEqual(errs, want) {
+				t.Errorf("Expected:\t%v", want)
+				t.Errorf("Got:\t\t%v", errs)
+			}
+			if max > test.max {
+				t.Errorf("%v invocations ran at once, limit was %v", max, test.max)
+			}
+			if (test.concurrency > 0) && (max < 2) {
+				t.Errorf("Invocations did not run concurrently")
+			}
+		})
+	}
+}
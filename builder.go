@@ -0,0 +1,129 @@
+package sub
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// CommandBuilder builds a Command using a fluent API. It is created by
+// Build:
+//
+//    var verbose bool
+//    cmd := sub.Build("list").
+//    	Desc("list the things").
+//    	Help("Usage: list [options]").
+//    	Flag("v", &verbose, "verbose output").
+//    	Run(func(args []string) error { ... }).
+//    	Command()
+type CommandBuilder struct {
+	name     string
+	desc     string
+	help     string
+	flags    []func(*flag.FlagSet)
+	run      func([]string) error
+	examples []string
+}
+
+// Build returns a CommandBuilder for a command with the given name.
+func Build(name string) *CommandBuilder {
+	return &CommandBuilder{name: name}
+}
+
+// Desc sets the short description of the command.
+func (b *CommandBuilder) Desc(desc string) *CommandBuilder {
+	b.desc = desc
+	return b
+}
+
+// Help sets the longer help message of the command.
+func (b *CommandBuilder) Help(help string) *CommandBuilder {
+	b.help = help
+	return b
+}
+
+// Example adds an example to the command.
+func (b *CommandBuilder) Example(example string) *CommandBuilder {
+	b.examples = append(b.examples, example)
+	return b
+}
+
+// Flag adds a flag to the command. The type of the flag is determined
+// by the type of value, which must be a bool, string, int, int64,
+// uint, uint64, float64, or time.Duration, a non-nil pointer to one of
+// those, or a flag.Value. If it is a pointer, the flag's value is
+// stored in the variable that it points to, and the variable's value
+// at the time Flag is called is used as the flag's default. Otherwise,
+// value is the default and the value of the flag is not accessible to
+// the command.
+//
+// Flag panics if value is of any other type.
+func (b *CommandBuilder) Flag(name string, value interface{}, usage string) *CommandBuilder {
+	if v, ok := value.(flag.Value); ok {
+		b.flags = append(b.flags, func(fset *flag.FlagSet) {
+			fset.Var(v, name, usage)
+		})
+		return b
+	}
+
+	def := value
+	target := func() interface{} {
+		return reflect.New(reflect.TypeOf(def)).Interface()
+	}
+	if rv := reflect.ValueOf(value); (rv.Kind() == reflect.Ptr) && !rv.IsNil() {
+		def = rv.Elem().Interface()
+		target = func() interface{} { return value }
+	}
+
+	switch def.(type) {
+	case bool, string, int, int64, uint, uint64, float64, time.Duration:
+	default:
+		panic(fmt.Errorf("sub: unsupported type %T for flag -%v", value, name))
+	}
+
+	b.flags = append(b.flags, func(fset *flag.FlagSet) {
+		switch p := target().(type) {
+		case *bool:
+			fset.BoolVar(p, name, def.(bool), usage)
+		case *string:
+			fset.StringVar(p, name, def.(string), usage)
+		case *int:
+			fset.IntVar(p, name, def.(int), usage)
+		case *int64:
+			fset.Int64Var(p, name, def.(int64), usage)
+		case *uint:
+			fset.UintVar(p, name, def.(uint), usage)
+		case *uint64:
+			fset.Uint64Var(p, name, def.(uint64), usage)
+		case *float64:
+			fset.Float64Var(p, name, def.(float64), usage)
+		case *time.Duration:
+			fset.DurationVar(p, name, def.(time.Duration), usage)
+		}
+	})
+	return b
+}
+
+// Run sets the function that is called when the command is run.
+func (b *CommandBuilder) Run(run func(args []string) error) *CommandBuilder {
+	b.run = run
+	return b
+}
+
+// Command returns the built Command. If Run was never called, running
+// the command does nothing. The builder can continue to be used after
+// Command is called without affecting previously built Commands.
+func (b *CommandBuilder) Command() Command {
+	flags := append(([]func(*flag.FlagSet))(nil), b.flags...)
+	run := b.run
+	if run == nil {
+		run = func([]string) error { return nil }
+	}
+
+	return Func(b.name, b.desc, b.help, func(fset *flag.FlagSet) {
+		for _, f := range flags {
+			f(fset)
+		}
+	}, run, b.examples...)
+}
@@ -0,0 +1,203 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+func runBuilt(t *testing.T, cmd sub.Command, args ...string) error {
+	t.Helper()
+
+	var c sub.Commander
+	c.Register(cmd)
+	return c.Run(append([]string{"subtest", cmd.Name()}, args...))
+}
+
+func TestBuildName(t *testing.T) {
+	cmd := sub.Build("list").Command()
+	if name := cmd.Name(); name != "list" {
+		t.Errorf("Expected:\t%q", "list")
+		t.Errorf("Got:\t\t%q", name)
+	}
+}
+
+func TestBuildDesc(t *testing.T) {
+	cmd := sub.Build("list").Desc("list things").Command()
+	if desc := cmd.Desc(); desc != "list things" {
+		t.Errorf("Expected:\t%q", "list things")
+		t.Errorf("Got:\t\t%q", desc)
+	}
+}
+
+func TestBuildHelp(t *testing.T) {
+	cmd := sub.Build("list").Help("Usage: list").Command()
+	if help := cmd.Help(); help != "Usage: list" {
+		t.Errorf("Expected:\t%q", "Usage: list")
+		t.Errorf("Got:\t\t%q", help)
+	}
+}
+
+func TestBuildExample(t *testing.T) {
+	cmd := sub.Build("list").Example("list -a").Example("list -l").Command()
+	example := cmd.(sub.ExampleProvider).Example()
+	if want := "list -a\n\nlist -l"; example != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", example)
+	}
+}
+
+func TestBuildRun(t *testing.T) {
+	var got []string
+	cmd := sub.Build("list").Run(func(args []string) error {
+		got = args
+		return errors.New("done")
+	}).Command()
+
+	err := runBuilt(t, cmd, "a", "b")
+	if (err == nil) || (err.Error() != "done") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+}
+
+func TestBuildNoRun(t *testing.T) {
+	err := runBuilt(t, sub.Build("list").Command())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestBuildFlagPointers(t *testing.T) {
+	var (
+		b   bool
+		s   = "default"
+		i   int
+		i64 int64
+		u   uint
+		u64 uint64
+		f   float64
+		d   time.Duration
+	)
+
+	cmd := sub.Build("list").
+		Flag("b", &b, "").
+		Flag("s", &s, "").
+		Flag("i", &i, "").
+		Flag("i64", &i64, "").
+		Flag("u", &u, "").
+		Flag("u64", &u64, "").
+		Flag("f", &f, "").
+		Flag("d", &d, "").
+		Command()
+
+	err := runBuilt(t, cmd, "-b", "-s", "set", "-i", "-1", "-i64", "-2", "-u", "3", "-u64", "4", "-f", "0.5", "-d", "1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !b || (s != "set") || (i != -1) || (i64 != -2) || (u != 3) || (u64 != 4) || (f != 0.5) || (d != time.Minute) {
+		t.Errorf("Got:\t%v %q %v %v %v %v %v %v", b, s, i, i64, u, u64, f, d)
+	}
+
+	err = runBuilt(t, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b || (s != "default") {
+		t.Errorf("Defaults not restored: %v %q", b, s)
+	}
+}
+
+func TestBuildFlagDefaults(t *testing.T) {
+	cmd := sub.Build("list").
+		Flag("v", false, "verbose output").
+		Flag("n", 3, "number of things").
+		Flag("timeout", time.Second, "how long to wait").
+		Command()
+
+	fset := flag.NewFlagSet("list", flag.ContinueOnError)
+	cmd.Flags(fset)
+
+	want := map[string]string{"v": "false", "n": "3", "timeout": "1s"}
+	for name, def := range want {
+		f := fset.Lookup(name)
+		if f == nil {
+			t.Errorf("Flag -%v not defined", name)
+			continue
+		}
+		if f.DefValue != def {
+			t.Errorf("Expected:\t%q", def)
+			t.Errorf("Got:\t\t%q", f.DefValue)
+		}
+	}
+}
+
+type upperValue struct {
+	s string
+}
+
+func (v *upperValue) String() string {
+	return v.s
+}
+
+func (v *upperValue) Set(s string) error {
+	v.s = strings.ToUpper(s)
+	return nil
+}
+
+func TestBuildFlagValue(t *testing.T) {
+	var v upperValue
+	cmd := sub.Build("list").Flag("name", &v, "").Command()
+
+	err := runBuilt(t, cmd, "-name", "value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.s != "VALUE" {
+		t.Errorf("Expected:\t%q", "VALUE")
+		t.Errorf("Got:\t\t%q", v.s)
+	}
+}
+
+func TestBuildFlagUnsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic")
+		}
+	}()
+
+	sub.Build("list").Flag("c", complex(1, 2), "")
+}
+
+func TestBuildHelpOutput(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout}
+	c.Register(c.HelpCmd())
+	c.Register(sub.Build("list").Desc("list things").Help("Usage: list [options]").Flag("v", false, "verbose output").Command())
+
+	err := c.Run([]string{"subtest", "help", "list"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Usage: list [options]
+
+Options:
+  -v	verbose output
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
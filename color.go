@@ -0,0 +1,59 @@
+package sub
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/DeedleFake/sub/internal/ansi"
+)
+
+// isTerminal returns true if w is a terminal. It is a variable so that
+// tests can replace it.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return (err == nil) && (info.Mode()&os.ModeCharDevice != 0)
+}
+
+// color returns true if help output should be styled.
+func (h *helpCmd) color() bool {
+	return !h.NoColor && isTerminal(h.output())
+}
+
+// style wraps text in the given ANSI escape sequence if help output
+// should be styled, and returns text unmodified otherwise.
+func (h *helpCmd) style(code, text string) string {
+	if !h.color() {
+		return text
+	}
+	return code + text + ansi.Reset
+}
+
+// styleDesc is like style, but returns text unmodified if code is
+// empty.
+func (h *helpCmd) styleDesc(code, text string) string {
+	if code == "" {
+		return text
+	}
+	return h.style(code, text)
+}
+
+// link returns name as an OSC 8 hyperlink if help output should be
+// styled, and returns name unmodified otherwise. Names that look like
+// URLs link to themselves and any other name links to its man page.
+func (h *helpCmd) link(name string) string {
+	if !h.color() {
+		return name
+	}
+
+	uri := name
+	if !strings.Contains(name, "://") {
+		uri = "man:" + name
+	}
+	return ansi.Link(uri, name)
+}
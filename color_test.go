@@ -0,0 +1,49 @@
+package sub_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestColor(t *testing.T) {
+	restore := sub.SetIsTerminal(func(io.Writer) bool { return true })
+	defer restore()
+
+	var cout bytes.Buffer
+
+	c := &sub.Commander{
+		Output: &cout,
+	}
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.Deprecated(sub.Func("old", "an old command", "", nil, nil), "no longer needed"),
+	)
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "Usage: subtest <subcommand> [subcommand arguments]\n" +
+		"\n" +
+		"\x1b[4mCommands:\x1b[0m\n" +
+		"\t\x1b[1mhelp\x1b[0m  show help for commands\n" +
+		"\t\x1b[1mold\x1b[0m   \x1b[2man old command [deprecated]\x1b[0m\n"
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	cout.Reset()
+	c.NoColor = true
+	err = c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.IndexByte(cout.Bytes(), '\x1b') >= 0 {
+		t.Errorf("Output contains escape sequences: %q", cout.String())
+	}
+}
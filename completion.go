@@ -0,0 +1,543 @@
+package sub
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type completionCmd struct {
+	c      *Commander
+	output string
+}
+
+// CompletionCmd returns a "completion" Command that writes shell
+// completion scripts for c. It takes the name of a shell, one of bash,
+// zsh, fish, or powershell, as its only argument. Like HelpCmd, it
+// must be manually registered if clients want it to be available.
+func (c *Commander) CompletionCmd() Command {
+	return &completionCmd{c: c}
+}
+
+func (cmd *completionCmd) Name() string {
+	return "completion"
+}
+
+func (cmd *completionCmd) Desc() string {
+	return "generate shell completion scripts"
+}
+
+func (cmd *completionCmd) Help() string {
+	return `Usage: completion [options] <bash|zsh|fish|powershell>
+
+completion writes a script that provides completion of commands and
+their flags for the given shell. The script can then be loaded by the
+shell, such as by running
+
+    source <(prog completion bash)
+
+in bash.`
+}
+
+func (cmd *completionCmd) Flags(fset *flag.FlagSet) {
+	fset.StringVar(&cmd.output, "output", "", "write the script to `file` instead of the output")
+}
+
+// completeArg is the argument to the completion command that the
+// generated scripts use to call back into the program.
+const completeArg = "__complete"
+
+// ValidateArgs exempts completion from MinArgs and MaxArgs.
+func (cmd *completionCmd) ValidateArgs(args []string) error {
+	return nil
+}
+
+func (cmd *completionCmd) Run(args []string) error {
+	if (len(args) > 0) && (args[0] == completeArg) {
+		// Candidates go to standard output by default, as that's where
+		// the scripts read them from.
+		w := cmd.c.Output
+		if w == nil {
+			w = os.Stdout
+		}
+		for _, candidate := range cmd.c.Complete(args[1:]) {
+			fmt.Fprintln(w, candidate)
+		}
+		return nil
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one shell name, got %v arguments", len(args))
+	}
+
+	var gen func(*Commander, io.Writer) error
+	switch args[0] {
+	case "bash":
+		gen = BashCompletion
+	case "zsh":
+		gen = ZshCompletion
+	case "fish":
+		gen = FishCompletion
+	case "powershell":
+		gen = PowerShellCompletion
+	default:
+		return fmt.Errorf("unsupported shell: %q", args[0])
+	}
+
+	if cmd.output == "" {
+		return gen(cmd.c, cmd.c.output())
+	}
+
+	file, err := os.Create(cmd.output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = gen(cmd.c, file)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// CompletionProvider is a Command that can complete the values of its
+// flags and arguments. The scripts written by the completion command
+// call back into the program to get completions for commands that
+// implement it.
+type CompletionProvider interface {
+	Command
+
+	// Complete returns the possible completions of prefix. flag is the
+	// name of the flag whose value is being completed, without any
+	// leading dashes, or an empty string if prefix is a positional
+	// argument.
+	Complete(flag string, prefix string) []string
+}
+
+// completionProviderOf returns cmd as a CompletionProvider, looking
+// through the package's wrappers, or nil if it isn't one.
+func completionProviderOf(cmd Command) CompletionProvider {
+	if w, ok := cmd.(interface{ completionProvider() CompletionProvider }); ok {
+		return w.completionProvider()
+	}
+	if p, ok := cmd.(CompletionProvider); ok {
+		return p
+	}
+	return nil
+}
+
+// Complete returns the possible completions of the last element of
+// args, which are the arguments that have been typed so far, not
+// including the program name. The last element may be empty. It
+// completes global flags, command names, and command flags, and asks
+// the command to complete flag values and positional arguments if it
+// implements CompletionProvider.
+//
+// Complete is the entry point used by the scripts that are written by
+// the completion command.
+func (c *Commander) Complete(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, prefix := args[:len(args)-1], args[len(args)-1]
+
+	global := flag.NewFlagSet(c.progName(), flag.ContinueOnError)
+	c.globalFlags(global)
+
+	i := skipFlags(global, words)
+	if i >= len(words) {
+		if strings.HasPrefix(prefix, "-") {
+			return completeFlags(global, prefix)
+		}
+
+		var names []string
+		for _, e := range c.entries() {
+			if !isHidden(e.cmd) && strings.HasPrefix(e.name, prefix) {
+				names = append(names, e.name)
+			}
+		}
+		return names
+	}
+
+	cmd := c.Lookup(words[i])
+	if cmd == nil {
+		return nil
+	}
+	words = words[i+1:]
+
+	if nested := nestedOf(cmd); nested != nil {
+		return nested.Commander.Complete(append(words, prefix))
+	}
+
+	fset := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	c.cmdFlags(cmd, fset)
+	provider := completionProviderOf(cmd)
+
+	if len(words) > 0 {
+		if name, ok := flagNeedsValue(fset, words[len(words)-1]); ok {
+			if provider == nil {
+				return nil
+			}
+			return provider.Complete(name, prefix)
+		}
+	}
+
+	if strings.HasPrefix(prefix, "-") {
+		if eq := strings.Index(prefix, "="); eq >= 0 {
+			if provider == nil {
+				return nil
+			}
+
+			name := strings.TrimLeft(prefix[:eq], "-")
+			var candidates []string
+			for _, candidate := range provider.Complete(name, prefix[eq+1:]) {
+				candidates = append(candidates, prefix[:eq+1]+candidate)
+			}
+			return candidates
+		}
+
+		return completeFlags(fset, prefix)
+	}
+
+	if provider == nil {
+		return nil
+	}
+	return provider.Complete("", prefix)
+}
+
+// skipFlags returns the index of the first element of words that is
+// not a flag in fset or the value of one.
+func skipFlags(fset *flag.FlagSet, words []string) int {
+	for i := 0; i < len(words); i++ {
+		if words[i] == "--" {
+			return i + 1
+		}
+		if !strings.HasPrefix(words[i], "-") {
+			return i
+		}
+		if _, ok := flagNeedsValue(fset, words[i]); ok {
+			i++
+		}
+	}
+	return len(words)
+}
+
+// flagNeedsValue returns the name of the flag in word and true if word
+// is a flag in fset that takes its value from the next argument.
+func flagNeedsValue(fset *flag.FlagSet, word string) (string, bool) {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return "", false
+	}
+
+	name := strings.TrimLeft(word, "-")
+	f := fset.Lookup(name)
+	if f == nil {
+		return "", false
+	}
+
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return "", false
+	}
+	return name, true
+}
+
+// completeFlags returns the flags in fset that start with prefix.
+func completeFlags(fset *flag.FlagSet, prefix string) []string {
+	var flags []string
+	fset.VisitAll(func(f *flag.Flag) {
+		if name := "-" + f.Name; strings.HasPrefix(name, prefix) {
+			flags = append(flags, name)
+		}
+	})
+	return flags
+}
+
+// completionFlag is a flag as presented by completion scripts.
+type completionFlag struct {
+	name  string
+	usage string
+}
+
+// completionEntry is a command name as presented by completion
+// scripts. Aliases get their own entries.
+type completionEntry struct {
+	name    string
+	desc    string
+	flags   []completionFlag
+	dynamic bool
+}
+
+// completionFlags returns the flags created by calling fill.
+func completionFlags(fill func(*flag.FlagSet)) []completionFlag {
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	fill(fset)
+
+	var flags []completionFlag
+	fset.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		flags = append(flags, completionFlag{name: "-" + f.Name, usage: usage})
+	})
+	return flags
+}
+
+// completionData returns the global flags of c and the entries for
+// every visible command name, sorted by name.
+func completionData(c *Commander) (global []completionFlag, entries []completionEntry) {
+	if c.hasGlobalFlags() {
+		global = completionFlags(c.globalFlags)
+	}
+
+	for _, e := range c.entries() {
+		if isHidden(e.cmd) {
+			continue
+		}
+
+		cmd := e.cmd
+		provider := completionProviderOf(cmd) != nil
+		nested := nestedOf(cmd) != nil
+		entries = append(entries, completionEntry{
+			name:    e.name,
+			desc:    cmd.Desc(),
+			flags:   completionFlags(func(fset *flag.FlagSet) { c.cmdFlags(cmd, fset) }),
+			dynamic: provider || nested,
+		})
+	}
+
+	return global, entries
+}
+
+// completionFuncName returns a version of name that can be used as
+// part of a shell function name.
+func completionFuncName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func flagNames(flags []completionFlag) string {
+	names := make([]string, 0, len(flags))
+	for _, f := range flags {
+		names = append(names, f.name)
+	}
+	return strings.Join(names, " ")
+}
+
+// BashCompletion writes a bash completion script for c to w. The
+// script completes the names of c's commands, its global flags, and the
+// flags of each command, and calls back into the program via the
+// completion command for commands that implement CompletionProvider.
+func BashCompletion(c *Commander, w io.Writer) error {
+	name := c.progName()
+	fn := "_" + completionFuncName(name) + "_completion"
+	global, entries := completionData(c)
+
+	cmds := make([]string, 0, len(entries))
+	for _, e := range entries {
+		cmds = append(cmds, e.name)
+	}
+
+	mw := &errWriter{w: w}
+	fmt.Fprintf(mw, "# bash completion for %v\n\n", name)
+	fmt.Fprintf(mw, "%v() {\n", fn)
+	fmt.Fprintf(mw, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(mw, "\tlocal cmd=\"\"\n")
+	fmt.Fprintf(mw, "\tlocal i\n")
+	fmt.Fprintf(mw, "\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	fmt.Fprintf(mw, "\t\tcase \"${COMP_WORDS[i]}\" in\n")
+	fmt.Fprintf(mw, "\t\t-*) ;;\n")
+	fmt.Fprintf(mw, "\t\t*) cmd=\"${COMP_WORDS[i]}\"; break ;;\n")
+	fmt.Fprintf(mw, "\t\tesac\n")
+	fmt.Fprintf(mw, "\tdone\n\n")
+	fmt.Fprintf(mw, "\tcase \"$cmd\" in\n")
+	fmt.Fprintf(mw, "\t\"\")\n")
+	fmt.Fprintf(mw, "\t\tCOMPREPLY=($(compgen -W %v -- \"$cur\"))\n", shellQuote(strings.TrimSpace(flagNames(global)+" "+strings.Join(cmds, " "))))
+	fmt.Fprintf(mw, "\t\t;;\n")
+	for _, e := range entries {
+		fmt.Fprintf(mw, "\t%v)\n", shellQuote(e.name))
+		if e.dynamic {
+			fmt.Fprintf(mw, "\t\tlocal IFS=$'\\n'\n")
+			fmt.Fprintf(mw, "\t\tCOMPREPLY=($(\"${COMP_WORDS[0]}\" completion %v \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null))\n", completeArg)
+		} else {
+			fmt.Fprintf(mw, "\t\tCOMPREPLY=($(compgen -W %v -- \"$cur\"))\n", shellQuote(flagNames(e.flags)))
+		}
+		fmt.Fprintf(mw, "\t\t;;\n")
+	}
+	fmt.Fprintf(mw, "\tesac\n")
+	fmt.Fprintf(mw, "}\n\n")
+	fmt.Fprintf(mw, "complete -o default -F %v %v\n", fn, shellQuote(name))
+
+	return mw.err
+}
+
+// zshDescribe formats a name and description for zsh's _describe and
+// _arguments functions.
+func zshDescribe(name, sep, desc, end string) string {
+	desc = strings.NewReplacer(`\`, `\\`, ":", `\:`, "[", `\[`, "]", `\]`).Replace(desc)
+	return shellQuote(name + sep + desc + end)
+}
+
+// ZshCompletion writes a zsh completion script for c to w. See
+// BashCompletion for details.
+func ZshCompletion(c *Commander, w io.Writer) error {
+	name := c.progName()
+	fn := "_" + completionFuncName(name)
+	global, entries := completionData(c)
+
+	mw := &errWriter{w: w}
+	fmt.Fprintf(mw, "#compdef %v\n\n", name)
+	fmt.Fprintf(mw, "%v() {\n", fn)
+	fmt.Fprintf(mw, "\tlocal -a commands\n")
+	fmt.Fprintf(mw, "\tcommands=(\n")
+	for _, e := range entries {
+		fmt.Fprintf(mw, "\t\t%v\n", zshDescribe(e.name, ":", e.desc, ""))
+	}
+	fmt.Fprintf(mw, "\t)\n\n")
+	fmt.Fprintf(mw, "\tlocal -a global\n")
+	fmt.Fprintf(mw, "\tglobal=(\n")
+	for _, f := range global {
+		fmt.Fprintf(mw, "\t\t%v\n", zshDescribe(f.name, "[", f.usage, "]"))
+	}
+	fmt.Fprintf(mw, "\t)\n\n")
+	fmt.Fprintf(mw, "\tlocal i cmd\n")
+	fmt.Fprintf(mw, "\tfor ((i = 2; i < CURRENT; i++)); do\n")
+	fmt.Fprintf(mw, "\t\tif [[ \"${words[i]}\" != -* ]]; then\n")
+	fmt.Fprintf(mw, "\t\t\tcmd=\"${words[i]}\"\n")
+	fmt.Fprintf(mw, "\t\t\tbreak\n")
+	fmt.Fprintf(mw, "\t\tfi\n")
+	fmt.Fprintf(mw, "\tdone\n\n")
+	fmt.Fprintf(mw, "\tif [[ -z \"$cmd\" ]]; then\n")
+	fmt.Fprintf(mw, "\t\t_arguments -s $global\n")
+	fmt.Fprintf(mw, "\t\t_describe 'command' commands\n")
+	fmt.Fprintf(mw, "\t\treturn\n")
+	fmt.Fprintf(mw, "\tfi\n\n")
+	fmt.Fprintf(mw, "\tcase \"$cmd\" in\n")
+	for _, e := range entries {
+		fmt.Fprintf(mw, "\t%v)\n", shellQuote(e.name))
+		if e.dynamic {
+			fmt.Fprintf(mw, "\t\tlocal -a candidates\n")
+			fmt.Fprintf(mw, "\t\tcandidates=(${(f)\"$(\"${words[1]}\" completion %v \"${(@)words[2,CURRENT]}\" 2>/dev/null)\"})\n", completeArg)
+			fmt.Fprintf(mw, "\t\tcompadd -a candidates\n")
+			fmt.Fprintf(mw, "\t\t;;\n")
+			continue
+		}
+		fmt.Fprintf(mw, "\t\t_arguments")
+		for _, f := range e.flags {
+			fmt.Fprintf(mw, " %v", zshDescribe(f.name, "[", f.usage, "]"))
+		}
+		fmt.Fprintf(mw, " '*:file:_files'\n")
+		fmt.Fprintf(mw, "\t\t;;\n")
+	}
+	fmt.Fprintf(mw, "\tesac\n")
+	fmt.Fprintf(mw, "}\n\n")
+	fmt.Fprintf(mw, "compdef %v %v\n", fn, shellQuote(name))
+
+	return mw.err
+}
+
+// fishQuote quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// FishCompletion writes a fish completion script for c to w. See
+// BashCompletion for details.
+func FishCompletion(c *Commander, w io.Writer) error {
+	name := c.progName()
+	global, entries := completionData(c)
+
+	cmds := make([]string, 0, len(entries))
+	for _, e := range entries {
+		cmds = append(cmds, e.name)
+	}
+	sort.Strings(cmds)
+
+	mw := &errWriter{w: w}
+	fmt.Fprintf(mw, "# fish completion for %v\n\n", name)
+	fmt.Fprintf(mw, "complete -c %v -f\n", fishQuote(name))
+	for _, f := range global {
+		fmt.Fprintf(mw, "complete -c %v -n '__fish_use_subcommand' -o %v -d %v\n", fishQuote(name), fishQuote(f.name[1:]), fishQuote(f.usage))
+	}
+	for _, e := range entries {
+		fmt.Fprintf(mw, "complete -c %v -n '__fish_use_subcommand' -a %v -d %v\n", fishQuote(name), fishQuote(e.name), fishQuote(e.desc))
+	}
+	for _, e := range entries {
+		cond := fishQuote("__fish_seen_subcommand_from " + e.name)
+		if e.dynamic {
+			fmt.Fprintf(mw, "complete -c %v -n %v -a %v\n", fishQuote(name), cond, fishQuote(fmt.Sprintf("(%v completion %v (commandline -opc)[2..-1] (commandline -ct))", name, completeArg)))
+			continue
+		}
+		for _, f := range e.flags {
+			fmt.Fprintf(mw, "complete -c %v -n %v -o %v -d %v\n", fishQuote(name), cond, fishQuote(f.name[1:]), fishQuote(f.usage))
+		}
+	}
+
+	return mw.err
+}
+
+// powershellQuote quotes s for PowerShell.
+func powershellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// PowerShellCompletion writes a PowerShell completion script for c to
+// w. See BashCompletion for details.
+func PowerShellCompletion(c *Commander, w io.Writer) error {
+	name := c.progName()
+	global, entries := completionData(c)
+
+	quoteAll := func(words []string) string {
+		quoted := make([]string, 0, len(words))
+		for _, word := range words {
+			quoted = append(quoted, powershellQuote(word))
+		}
+		return strings.Join(quoted, ", ")
+	}
+
+	top := make([]string, 0, len(global)+len(entries))
+	for _, f := range global {
+		top = append(top, f.name)
+	}
+	for _, e := range entries {
+		top = append(top, e.name)
+	}
+
+	mw := &errWriter{w: w}
+	fmt.Fprintf(mw, "# powershell completion for %v\n\n", name)
+	fmt.Fprintf(mw, "Register-ArgumentCompleter -Native -CommandName %v -ScriptBlock {\n", powershellQuote(name))
+	fmt.Fprintf(mw, "\tparam($wordToComplete, $commandAst, $cursorPosition)\n\n")
+	fmt.Fprintf(mw, "\t$commands = @(%v)\n", quoteAll(top))
+	fmt.Fprintf(mw, "\t$flags = @{\n")
+	for _, e := range entries {
+		names := make([]string, 0, len(e.flags))
+		for _, f := range e.flags {
+			names = append(names, f.name)
+		}
+		fmt.Fprintf(mw, "\t\t%v = @(%v)\n", powershellQuote(e.name), quoteAll(names))
+	}
+	fmt.Fprintf(mw, "\t}\n\n")
+	fmt.Fprintf(mw, "\t$elements = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })\n")
+	fmt.Fprintf(mw, "\t$cmd = $elements | Where-Object { -not $_.StartsWith('-') -and $_ -ne $wordToComplete } | Select-Object -First 1\n")
+	fmt.Fprintf(mw, "\tif ($cmd) {\n")
+	fmt.Fprintf(mw, "\t\t$candidates = $flags[$cmd]\n")
+	fmt.Fprintf(mw, "\t} else {\n")
+	fmt.Fprintf(mw, "\t\t$candidates = $commands\n")
+	fmt.Fprintf(mw, "\t}\n\n")
+	fmt.Fprintf(mw, "\t$candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	fmt.Fprintf(mw, "\t\t[System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+	fmt.Fprintf(mw, "\t}\n")
+	fmt.Fprintf(mw, "}\n")
+
+	return mw.err
+}
@@ -0,0 +1,307 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+func newCompletionCommander(cout *bytes.Buffer) *sub.Commander {
+	c := &sub.Commander{
+		Output: cout,
+		Flags: func(fset *flag.FlagSet) {
+			fset.Bool("v", false, "verbose output")
+		},
+	}
+	c.RegisterAll(c.HelpCmd(), c.CompletionCmd(), &testCmd{})
+	return c
+}
+
+func TestCompletionCmd(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{
+			shell: "bash",
+			want: []string{
+				"_subtest_completion()",
+				"compgen -W '-v completion help test'",
+				"compgen -W '-flag'",
+				"complete -o default -F _subtest_completion 'subtest'",
+			},
+		},
+		{
+			shell: "zsh",
+			want: []string{
+				"#compdef subtest",
+				"'test:a simple test'",
+				"'-flag[a flag test]'",
+				"compdef _subtest 'subtest'",
+			},
+		},
+		{
+			shell: "fish",
+			want: []string{
+				"complete -c 'subtest' -n '__fish_use_subcommand' -a 'test' -d 'a simple test'",
+				"complete -c 'subtest' -n '__fish_seen_subcommand_from test' -o 'flag' -d 'a flag test'",
+			},
+		},
+		{
+			shell: "powershell",
+			want: []string{
+				"-CommandName 'subtest'",
+				"$commands = @('-v', 'completion', 'help', 'test')",
+				"'test' = @('-flag')",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.shell, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			c := newCompletionCommander(&cout)
+
+			err := c.Run([]string{"subtest", "completion", test.shell})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			out := cout.String()
+			for _, want := range test.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("Output is missing %q:\n%v", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCompletionCmdOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "subtest.bash")
+
+	var cout bytes.Buffer
+	c := newCompletionCommander(&cout)
+
+	err = c.Run([]string{"subtest", "completion", "-output", path, "bash"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cout.Len() != 0 {
+		t.Errorf("Output was written to: %q", cout.String())
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("subtest")) || !bytes.Contains(data, []byte("test")) {
+		t.Errorf("Unexpected script: %s", data)
+	}
+
+	err = c.Run([]string{"subtest", "completion", "tcsh"})
+	if err == nil {
+		t.Errorf("Expected error for unsupported shell")
+	}
+}
+
+func TestCompletionFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		gen   func(*sub.Commander, io.Writer) error
+		first string
+	}{
+		{name: "Bash", gen: sub.BashCompletion, first: "# bash completion for subtest"},
+		{name: "Zsh", gen: sub.ZshCompletion, first: "#compdef subtest"},
+		{name: "Fish", gen: sub.FishCompletion, first: "# fish completion for subtest"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			c := newCompletionCommander(&cout)
+			c.SetName("subtest")
+
+			var buf bytes.Buffer
+			err := test.gen(c, &buf)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cout.Len() != 0 {
+				t.Errorf("Output was written to: %q", cout.String())
+			}
+
+			out := buf.String()
+			if first := strings.SplitN(out, "\n", 2)[0]; first != test.first {
+				t.Errorf("Expected:\t%q", test.first)
+				t.Errorf("Got:\t\t%q", first)
+			}
+			if !strings.Contains(out, "test") || !strings.Contains(out, "flag") {
+				t.Errorf("Script is missing commands or flags:\n%v", out)
+			}
+
+			for _, pair := range []string{"{}", "[]"} {
+				open, close := strings.Count(out, pair[:1]), strings.Count(out, pair[1:])
+				if open != close {
+					t.Errorf("Unbalanced %v: %v opening, %v closing", pair, open, close)
+				}
+			}
+		})
+	}
+}
+
+type openCmd struct{}
+
+func (cmd openCmd) Name() string {
+	return "open"
+}
+
+func (cmd openCmd) Desc() string {
+	return "open a file"
+}
+
+func (cmd openCmd) Help() string {
+	return ""
+}
+
+func (cmd openCmd) Flags(fset *flag.FlagSet) {
+	fset.String("file", "", "the file to open")
+	fset.String("mode", "r", "the mode to open the file with")
+	fset.Bool("v", false, "verbose output")
+}
+
+func (cmd openCmd) Run(args []string) error {
+	return nil
+}
+
+func (cmd openCmd) Complete(flag, prefix string) []string {
+	var candidates []string
+	if flag == "file" {
+		for _, name := range []string{"foo.txt", "foo.go", "bar.txt"} {
+			if strings.HasPrefix(name, prefix) {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+	return candidates
+}
+
+func TestComplete(t *testing.T) {
+	var c sub.Commander
+	c.Flags = func(fset *flag.FlagSet) {
+		fset.String("config", "", "configuration file")
+	}
+	c.RegisterAll(c.HelpCmd(), c.CompletionCmd(), openCmd{}, &testCmd{})
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "Commands", args: []string{""}, want: []string{"completion", "help", "open", "test"}},
+		{name: "Command Prefix", args: []string{"te"}, want: []string{"test"}},
+		{name: "Global Flags", args: []string{"-"}, want: []string{"-config"}},
+		{name: "After Global Flag", args: []string{"-config", "file", "o"}, want: []string{"open"}},
+		{name: "Command Flags", args: []string{"open", "-"}, want: []string{"-file", "-mode", "-v"}},
+		{name: "Flag Value", args: []string{"open", "-file", "foo"}, want: []string{"foo.txt", "foo.go"}},
+		{name: "Flag Value With Equals", args: []string{"open", "-file=b"}, want: []string{"-file=bar.txt"}},
+		{name: "Other Flag Value", args: []string{"open", "-mode", ""}},
+		{name: "After Bool Flag", args: []string{"open", "-v", ""}},
+		{name: "No Provider", args: []string{"test", "-flag", ""}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := c.Complete(test.args)
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("Expected:\t%q", test.want)
+				t.Errorf("Got:\t\t%q", got)
+			}
+		})
+	}
+}
+
+func TestCompleteInterspersed(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout, Interspersed: true}
+	c.RegisterAll(c.CompletionCmd(), openCmd{})
+
+	err := c.Run([]string{"subtest", "completion", "__complete", "open", "-x", "-"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "-file\n-mode\n-v\n"; cout.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cout.String())
+	}
+}
+
+func TestCompleteWrapped(t *testing.T) {
+	var c sub.Commander
+	c.RegisterGroup("Files", sub.Timed(sub.Hidden(openCmd{}), func(string, time.Duration) {}))
+
+	got := c.Complete([]string{"open", "-file", "foo"})
+	if want := []string{"foo.txt", "foo.go"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+
+	var script bytes.Buffer
+	var visible sub.Commander
+	visible.RegisterGroup("Files", openCmd{})
+	err := sub.BashCompletion(&visible, &script)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "completion __complete"; !strings.Contains(script.String(), want) {
+		t.Errorf("Script is missing %q:\n%v", want, script.String())
+	}
+}
+
+func TestCompleteCallback(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout}
+	c.RegisterAll(c.CompletionCmd(), openCmd{})
+
+	err := c.Run([]string{"subtest", "completion", "__complete", "open", "-file", "f"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "foo.txt\nfoo.go\n"; cout.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cout.String())
+	}
+
+	cout.Reset()
+	err = c.Run([]string{"subtest", "completion", "bash"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := `"${COMP_WORDS[0]}" completion __complete`; !strings.Contains(cout.String(), want) {
+		t.Errorf("Script is missing %q:\n%v", want, cout.String())
+	}
+}
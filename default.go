@@ -0,0 +1,58 @@
+package sub
+
+import "sync"
+
+// DefaultCommander is the Commander used by the package-level
+// functions, such as Register and Run, which let small programs use
+// the package without creating a Commander of their own.
+//
+// It is nil until one of those functions is first called, at which
+// point a new Commander with no fields set is created, meaning that it
+// writes its output to os.Stderr. Clients that want to configure it
+// can instead set it themselves before calling any of them. As with
+// any other Commander, its fields are not guarded against concurrent
+// use, so clients that modify it while it is in use, including by
+// setting DefaultCommander itself, must provide their own locking.
+var DefaultCommander *Commander
+
+var defaultOnce sync.Once
+
+// defaultCommander returns DefaultCommander, creating it first if it
+// hasn't been set.
+func defaultCommander() *Commander {
+	defaultOnce.Do(func() {
+		if DefaultCommander == nil {
+			DefaultCommander = new(Commander)
+		}
+	})
+	return DefaultCommander
+}
+
+// Register registers cmd with DefaultCommander. See
+// Commander.Register.
+func Register(cmd Command) {
+	defaultCommander().Register(cmd)
+}
+
+// Run runs DefaultCommander against args. See Commander.Run.
+func Run(args []string) error {
+	return defaultCommander().Run(args)
+}
+
+// RunOS runs DefaultCommander against the program's command-line
+// arguments and exits. See Commander.RunOS.
+func RunOS() {
+	defaultCommander().RunOS()
+}
+
+// HelpCmd returns a help Command for DefaultCommander. See
+// Commander.HelpCmd.
+func HelpCmd() Command {
+	return defaultCommander().HelpCmd()
+}
+
+// SetVersion sets the version of DefaultCommander. See
+// Commander.SetVersion.
+func SetVersion(version string) {
+	defaultCommander().SetVersion(version)
+}
@@ -0,0 +1,41 @@
+package sub_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestDefaultCommander(t *testing.T) {
+	var got []string
+	sub.Register(sub.HelpCmd())
+	sub.Register(sub.Func("echo", "echo arguments", "", nil, func(args []string) error {
+		got = args
+		return nil
+	}))
+	sub.SetVersion("1.0.0")
+
+	err := sub.Run([]string{"subtest", "echo", "a", "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+
+	c := sub.DefaultCommander
+	if c == nil {
+		t.Fatal("DefaultCommander was not initialized")
+	}
+	if v := c.Version(); v != "1.0.0" {
+		t.Errorf("Expected:\t%q", "1.0.0")
+		t.Errorf("Got:\t\t%q", v)
+	}
+	for _, name := range []string{"echo", "help", "version"} {
+		if !c.Has(name) {
+			t.Errorf("Command %q is not registered", name)
+		}
+	}
+}
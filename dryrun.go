@@ -0,0 +1,47 @@
+package sub
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DryRunner is a Command that handles dry runs itself. When a
+// Commander's DryRun field is set, a DryRunner's DryRun method is
+// called in place of its Run method, and nothing is printed on its
+// behalf. Neither the Commander's nor the command's hooks nor any
+// middleware are run during a dry run, whether or not the command is
+// a DryRunner.
+type DryRunner interface {
+	Command
+
+	// DryRun is passed the same arguments that Run would have been. It
+	// should report what Run would do without doing it.
+	DryRun(args []string) error
+}
+
+// dryRunnerOf returns cmd as a DryRunner, looking through the
+// package's wrappers, or nil if it isn't one.
+func dryRunnerOf(cmd Command) DryRunner {
+	if w, ok := cmd.(interface{ dryRunner() DryRunner }); ok {
+		return w.dryRunner()
+	}
+	if d, ok := cmd.(DryRunner); ok {
+		return d
+	}
+	return nil
+}
+
+// dryRun returns true if c or any of its parents have DryRun set.
+func (c *Commander) dryRun() bool {
+	return c.DryRun || ((c.parent != nil) && c.parent.dryRun())
+}
+
+// dryRunCommand handles a dry run of cmd with args.
+func (c *Commander) dryRunCommand(cmd Command, args []string) error {
+	if d := dryRunnerOf(cmd); d != nil {
+		return d.DryRun(args)
+	}
+
+	fmt.Fprintf(c.output(), "[dry-run] would execute: %v\n", strings.Join(append([]string{cmd.Name()}, args...), " "))
+	return nil
+}
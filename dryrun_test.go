@@ -0,0 +1,88 @@
+package sub_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type dryRunCmd struct {
+	sub.Command
+	dry []string
+}
+
+func (cmd *dryRunCmd) DryRun(args []string) error {
+	cmd.dry = args
+	return nil
+}
+
+func TestDryRun(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	run := func(name string) func([]string) error {
+		return func([]string) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+
+	required := &requiredCmd{}
+	custom := &dryRunCmd{Command: sub.Func("deploy", "", "", nil, run("deploy"))}
+
+	var inner sub.Commander
+	inner.Register(sub.Func("get", "", "", nil, run("get")))
+
+	c := &sub.Commander{Output: &out, DryRun: true, Silent: true}
+	c.PreRun = func(cmd sub.Command, args []string) error {
+		ran = append(ran, "prerun")
+		return nil
+	}
+	c.RegisterAll(
+		sub.Func("create", "", "", nil, run("create")),
+		required,
+		sub.Hidden(custom),
+		inner.AsCommand("config", "manage configuration"),
+	)
+
+	err := c.Run([]string{"subtest", "create", "foo", "bar"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "[dry-run] would execute: create foo bar\n"; out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+
+	err = c.Run([]string{"subtest", "login", "-token", "x"})
+	if (err == nil) || !strings.Contains(err.Error(), "-server") {
+		t.Errorf("Validation skipped: %v", err)
+	}
+
+	out.Reset()
+	err = c.Run([]string{"subtest", "deploy", "prod"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(custom.dry) != "[prod]" {
+		t.Errorf("DryRun called with %q", custom.dry)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Output:\t%q", out.String())
+	}
+
+	err = c.Run([]string{"subtest", "config", "get", "key"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "[dry-run] would execute: get key\n"; out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+
+	if (len(ran) != 0) || required.ran {
+		t.Errorf("Commands ran: %q", ran)
+	}
+}
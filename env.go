@@ -0,0 +1,47 @@
+package sub
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envName returns the name of the environment variable that is used
+// for the flag named flagName of the command named cmdName.
+func (c *Commander) envName(cmdName, flagName string) string {
+	name := c.EnvPrefix + cmdName + "_" + flagName
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// applyEnv sets any flags in fset, which should already have been
+// parsed, that were not set on the command line from the environment.
+// It does nothing if c.EnvPrefix is empty.
+func (c *Commander) applyEnv(cmdName string, fset *flag.FlagSet) error {
+	if c.EnvPrefix == "" {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	fset.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+	fset.VisitAll(func(f *flag.Flag) {
+		if (err != nil) || set[f.Name] {
+			return
+		}
+
+		env := c.envName(cmdName, f.Name)
+		val, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+
+		if serr := fset.Set(f.Name, val); serr != nil {
+			err = fmt.Errorf("invalid value %q for flag -%v from $%v: %v", val, f.Name, env, serr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,74 @@
+package sub_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	os.Setenv("SUBTEST_LOGIN_API_TOKEN", "from-env")
+	defer os.Unsetenv("SUBTEST_LOGIN_API_TOKEN")
+
+	tests := []struct {
+		name   string
+		prefix string
+		args   []string
+		token  string
+		server string
+	}{
+		{
+			name:   "From Environment",
+			prefix: "SUBTEST_",
+			args:   []string{"subtest", "login"},
+			token:  "from-env",
+			server: "localhost",
+		},
+		{
+			name:   "Command Line Wins",
+			prefix: "SUBTEST_",
+			args:   []string{"subtest", "login", "-api-token", "from-args"},
+			token:  "from-args",
+			server: "localhost",
+		},
+		{
+			name:   "Disabled",
+			args:   []string{"subtest", "login"},
+			server: "localhost",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var token, server string
+
+			c := &sub.Commander{EnvPrefix: test.prefix}
+			c.Register(sub.Func(
+				"login",
+				"",
+				"",
+				func(fset *flag.FlagSet) {
+					fset.StringVar(&token, "api-token", "", "authentication token")
+					fset.StringVar(&server, "server", "localhost", "server to log in to")
+				},
+				func([]string) error { return nil },
+			))
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if token != test.token {
+				t.Errorf("Expected:\t%q", test.token)
+				t.Errorf("Got:\t\t%q", token)
+			}
+			if server != test.server {
+				t.Errorf("Expected:\t%q", test.server)
+				t.Errorf("Got:\t\t%q", server)
+			}
+		})
+	}
+}
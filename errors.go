@@ -0,0 +1,107 @@
+package sub
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ErrNoCommand is returned by Run when no command is given, there is
+// no Default command or OnNoArgs function, and the Commander's
+// DisableBuiltinHelp field is set.
+var ErrNoCommand = errors.New("no command given")
+
+// UnknownCommandError is returned by Run when the named command is not
+// registered.
+type UnknownCommandError struct {
+	// Name is the name of the command that was given.
+	Name string
+}
+
+func (err *UnknownCommandError) Error() string {
+	return fmt.Sprintf("no such command: %q", err.Name)
+}
+
+// AmbiguousCommandError is returned by Run when the Commander's
+// Abbreviate field is set and the command that was given is a prefix
+// of more than one command.
+type AmbiguousCommandError struct {
+	// Name is the name of the command that was given.
+	Name string
+
+	// Matches are the names of the commands that Name is a prefix of,
+	// sorted.
+	Matches []string
+}
+
+func (err *AmbiguousCommandError) Error() string {
+	return fmt.Sprintf("ambiguous command %q: could be %v", err.Name, strings.Join(err.Matches, ", "))
+}
+
+// FlagParseError is returned by Run when the global flags or a
+// command's flags can't be parsed, including when a flag's value from
+// the environment is invalid. It distinguishes mistakes in the usage
+// of flags from errors returned by the commands themselves.
+type FlagParseError struct {
+	// Cmd is the name of the command whose flags couldn't be parsed.
+	// It is empty if the global flags couldn't be parsed.
+	Cmd string
+
+	// Err is the underlying error, usually the one returned by the
+	// flag package.
+	Err error
+}
+
+func (err *FlagParseError) Error() string {
+	if err.Cmd == "" {
+		return fmt.Sprintf("invalid global flags: %v", err.Err)
+	}
+
+	return fmt.Sprintf("invalid flags for %v: %v", err.Cmd, err.Err)
+}
+
+// Unwrap returns the underlying error.
+func (err *FlagParseError) Unwrap() error {
+	return err.Err
+}
+
+// Is returns true if target is flag.ErrHelp and err's underlying error
+// is as well, or if target is a *FlagParseError for the same command.
+// A target with a nil Err matches any underlying error, so
+//
+//    errors.Is(err, &sub.FlagParseError{Cmd: "build"})
+//
+// checks for a problem with the flags of the build command.
+func (err *FlagParseError) Is(target error) bool {
+	if target == flag.ErrHelp {
+		return err.Err == flag.ErrHelp
+	}
+
+	t, ok := target.(*FlagParseError)
+	return ok && (t.Cmd == err.Cmd) && ((t.Err == nil) || (t.Err == err.Err))
+}
+
+// PanicError is returned by Run when a command panics and the
+// Commander's Recover field is set.
+type PanicError struct {
+	// Value is the value that the command panicked with.
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine that panicked, as
+	// returned by runtime/debug.Stack.
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+// Unwrap returns the value that the command panicked with if it is an
+// error, and nil otherwise.
+func (err *PanicError) Unwrap() error {
+	if e, ok := err.Value.(error); ok {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package sub_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		check func(error) bool
+	}{
+		{
+			name:  "Global Help",
+			args:  []string{"subtest", "-h"},
+			check: func(err error) bool { return err == flag.ErrHelp },
+		},
+		{
+			name:  "Command Help",
+			args:  []string{"subtest", "test", "-help"},
+			check: func(err error) bool { return err == flag.ErrHelp },
+		},
+		{
+			name: "Unknown Command",
+			args: []string{"subtest", "missing"},
+			check: func(err error) bool {
+				var unknown *sub.UnknownCommandError
+				return errors.As(err, &unknown) && (unknown.Name == "missing")
+			},
+		},
+		{
+			name: "Global Flag Parse Error",
+			args: []string{"subtest", "-unknown", "test"},
+			check: func(err error) bool {
+				var parse *sub.FlagParseError
+				return errors.As(err, &parse) && (parse.Cmd == "") && (err.Error() == "invalid global flags: flag provided but not defined: -unknown")
+			},
+		},
+		{
+			name: "Command Flag Parse Error",
+			args: []string{"subtest", "test", "-unknown"},
+			check: func(err error) bool {
+				var parse *sub.FlagParseError
+				return errors.As(err, &parse) && (parse.Cmd == "test") && (err.Error() == "invalid flags for test: flag provided but not defined: -unknown")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			c := &sub.Commander{Output: &cout, Silent: true}
+			c.Register(&testCmd{w: &cout})
+
+			err := c.Run(test.args)
+			if !test.check(err) {
+				t.Errorf("Unexpected error: %#v", err)
+			}
+		})
+	}
+}
+
+func TestFlagParseError(t *testing.T) {
+	os.Setenv("SUBTEST_COUNT_N", "many")
+	defer os.Unsetenv("SUBTEST_COUNT_N")
+
+	count := sub.Func("count", "", "", func(fset *flag.FlagSet) {
+		fset.Int("n", 0, "how many")
+	}, func([]string) error { return nil })
+
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		cmd  string
+		msg  string
+	}{
+		{
+			name: "Unknown Global Flag",
+			args: []string{"subtest", "-unknown", "count"},
+			msg:  "invalid global flags: flag provided but not defined: -unknown",
+		},
+		{
+			name: "Invalid Global Value",
+			args: []string{"subtest", "-v=maybe", "count"},
+			msg:  `invalid global flags: invalid boolean value "maybe" for -v: parse error`,
+		},
+		{
+			name: "Unknown Command Flag",
+			args: []string{"subtest", "count", "-unknown"},
+			cmd:  "count",
+			msg:  "invalid flags for count: flag provided but not defined: -unknown",
+		},
+		{
+			name: "Invalid Command Value",
+			args: []string{"subtest", "count", "-n", "many"},
+			cmd:  "count",
+			msg:  `invalid flags for count: invalid value "many" for flag -n: parse error`,
+		},
+		{
+			name: "Missing Value",
+			args: []string{"subtest", "count", "-n"},
+			cmd:  "count",
+			msg:  "invalid flags for count: flag needs an argument: -n",
+		},
+		{
+			name: "Invalid Environment Value",
+			args: []string{"subtest", "count"},
+			env:  "SUBTEST_",
+			cmd:  "count",
+			msg:  `invalid flags for count: invalid value "many" for flag -n from $SUBTEST_COUNT_N: parse error`,
+		},
+		{
+			name: "Nested Command Flag",
+			args: []string{"subtest", "nested", "count", "-n", "many"},
+			cmd:  "count",
+			msg:  `invalid flags for count: invalid value "many" for flag -n: parse error`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var nested sub.Commander
+			nested.Register(count)
+
+			c := &sub.Commander{
+				Silent:    true,
+				EnvPrefix: test.env,
+				Flags: func(fset *flag.FlagSet) {
+					fset.Bool("v", false, "verbose output")
+				},
+			}
+			c.RegisterAll(count, nested.AsCommand("nested", "nested commands"))
+
+			err := c.Run(test.args)
+			var parse *sub.FlagParseError
+			if !errors.As(err, &parse) {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+			if parse.Cmd != test.cmd {
+				t.Errorf("Expected:\t%q", test.cmd)
+				t.Errorf("Got:\t\t%q", parse.Cmd)
+			}
+			if err.Error() != test.msg {
+				t.Errorf("Expected:\t%q", test.msg)
+				t.Errorf("Got:\t\t%q", err.Error())
+			}
+			if !errors.Is(err, &sub.FlagParseError{Cmd: test.cmd}) {
+				t.Errorf("errors.Is did not match the command")
+			}
+			if errors.Is(err, &sub.FlagParseError{Cmd: "other"}) {
+				t.Errorf("errors.Is matched the wrong command")
+			}
+			if errors.Is(err, flag.ErrHelp) {
+				t.Errorf("errors.Is matched flag.ErrHelp")
+			}
+		})
+	}
+
+	err := &sub.FlagParseError{Err: flag.ErrHelp}
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("errors.Is did not match flag.ErrHelp")
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	var cout, errout bytes.Buffer
+
+	c := &sub.Commander{
+		Output: &cout,
+		ErrorHandler: func(w io.Writer, err error) {
+			json.NewEncoder(&errout).Encode(map[string]string{"error": err.Error()})
+		},
+	}
+	c.Register(sub.Func("fail", "", "", nil, func([]string) error {
+		return errors.New("something broke")
+	}))
+	c.Register(sub.Func("ok", "", "", nil, func([]string) error {
+		return nil
+	}))
+
+	c.Run([]string{"subtest", "fail"})
+	c.Run([]string{"subtest", "ok"})
+	c.Run([]string{"subtest", "fail", "-unknown"})
+	c.Run([]string{"subtest", "-h"})
+
+	var got []string
+	dec := json.NewDecoder(&errout)
+	for dec.More() {
+		var v map[string]string
+		err := dec.Decode(&v)
+		if err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		got = append(got, v["error"])
+	}
+
+	want := []string{
+		"something broke",
+		"invalid flags for fail: flag provided but not defined: -unknown",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		out  string
+	}{
+		{name: "Plain", err: errors.New("failed"), out: "Error: failed\n"},
+		{name: "Exit Error", err: &sub.ExitError{Code: 2, Err: errors.New("usage")}, out: "Error: usage\n"},
+		{name: "Silent Exit Error", err: &sub.ExitError{Code: 2}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			sub.DefaultErrorHandler(&out, test.err)
+			if out.String() != test.out {
+				t.Errorf("Expected:\t%q", test.out)
+				t.Errorf("Got:\t\t%q", out.String())
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		prefix string
+		out    string
+	}{
+		{name: "Plain", err: errors.New("failed"), out: "Error: failed\n"},
+		{name: "Prefix", err: errors.New("failed"), prefix: "subtest: ", out: "subtest: failed\n"},
+		{name: "Help", err: flag.ErrHelp},
+		{name: "Unknown Command", err: &sub.UnknownCommandError{Name: "tset"}, out: "Error: No such command: \"tset\"\nDid you mean: test?\n"},
+		{name: "Global Flags", err: &sub.FlagParseError{Err: errors.New("bad")}, out: "Error: invalid global flags: bad\nRun 'subtest help' for usage.\n"},
+		{name: "Command Flags", err: &sub.FlagParseError{Cmd: "test", Err: errors.New("bad")}, out: "Error: invalid flags for test: bad\nRun 'subtest help test' for usage.\n"},
+		{name: "Exit Error", err: &sub.ExitError{Code: 2, Err: errors.New("usage")}, out: "Error: usage\n"},
+		{name: "Wrapped Exit Error", err: &sub.ExitError{Code: 2, Err: &sub.UnknownCommandError{Name: "other"}}, out: "Error: No such command: \"other\"\n"},
+		{name: "Silent Exit Error", err: &sub.ExitError{Code: 2}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out, errOut bytes.Buffer
+			c := &sub.Commander{Output: &out, ErrOutput: &errOut, ErrorPrefix: test.prefix}
+			c.SetName("subtest")
+			c.Register(&testCmd{})
+
+			c.PrintError(test.err)
+			if errOut.String() != test.out {
+				t.Errorf("Expected:\t%q", test.out)
+				t.Errorf("Got:\t\t%q", errOut.String())
+			}
+			if out.Len() != 0 {
+				t.Errorf("Output:\t%q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package sub
+
+import (
+	"context"
+	"flag"
+)
+
+// ParsedCommand is the result of parsing a set of arguments with
+// ParseCommand. It can be run any number of times with Execute without
+// parsing the arguments again.
+type ParsedCommand struct {
+	// Command is the command that the arguments resolved to.
+	Command Command
+
+	// Args are the arguments to pass to the command.
+	Args []string
+
+	// GlobalFlags holds the values of the global flags that were set,
+	// keyed by flag name.
+	GlobalFlags map[string]string
+
+	// Flags holds the values of the command's flags that were set,
+	// either on the command line or from the environment, keyed by flag
+	// name.
+	Flags map[string]string
+}
+
+// ParseCommand is like Parse, but returns the result as a
+// ParsedCommand that can be run with Execute. As with Parse, it may
+// return nil for both the ParsedCommand and the error.
+//
+// Caching ParsedCommands is the caller's responsibility. A convenient
+// key is the contents of args. The Commander doesn't keep track of
+// them itself, and, apart from its name and the FlagSet returned by
+// GlobalFlagSet, keeps no state between runs.
+func (c *Commander) ParseCommand(args []string) (*ParsedCommand, error) {
+	cmd, global, fset, args, err := c.parse(args)
+	if (err != nil) || (cmd == nil) {
+		return nil, err
+	}
+
+	return &ParsedCommand{
+		Command:     cmd,
+		Args:        args,
+		GlobalFlags: setFlags(global),
+		Flags:       setFlags(fset),
+	}, nil
+}
+
+// setFlags returns the values of the flags that were set in fset. It
+// returns nil if fset is nil.
+func setFlags(fset *flag.FlagSet) map[string]string {
+	if fset == nil {
+		return nil
+	}
+
+	values := make(map[string]string)
+	fset.Visit(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return values
+}
+
+// Execute runs a command that was previously parsed by ParseCommand in
+// the same way as Run would have, but without parsing the arguments
+// again. Instead, the command's flags are reset to their defaults and
+// then set to the values recorded in p, which is much cheaper. This is
+// useful for programs that run the same arguments repeatedly.
+func (c *Commander) Execute(p *ParsedCommand) error {
+	err := c.execute(p)
+	c.handleError(err)
+	return err
+}
+
+func (c *Commander) execute(p *ParsedCommand) error {
+	ctx := context.Background()
+
+	global := flag.NewFlagSet(c.progName(), flag.ContinueOnError)
+	c.globalFlags(global)
+	err := replayFlags(global, p.GlobalFlags)
+	if err != nil {
+		return &FlagParseError{Err: err}
+	}
+	ctx = context.WithValue(ctx, GlobalFlagsKey, global)
+
+	if p.Flags != nil {
+		fset := flag.NewFlagSet(p.Command.Name(), flag.ContinueOnError)
+		c.cmdFlags(p.Command, fset)
+		err := replayFlags(fset, p.Flags)
+		if err != nil {
+			return &FlagParseError{Cmd: p.Command.Name(), Err: err}
+		}
+		ctx = context.WithValue(ctx, flagSetKey{}, fset)
+	}
+
+	return c.DispatchContext(ctx, p.Command, p.Args)
+}
+
+// replayFlags sets the flags of fset to values.
+func replayFlags(fset *flag.FlagSet, values map[string]string) error {
+	for name, value := range values {
+		err := fset.Set(name, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package sub_test
+
+import (
+	"context"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestExecute(t *testing.T) {
+	var global, name string
+	var args []string
+	var verbose bool
+
+	c := sub.NewCommander(sub.WithFlags(func(fset *flag.FlagSet) {
+		fset.StringVar(&global, "global", "default", "a global flag")
+	}))
+	c.Register(sub.Func("greet", "", "", func(fset *flag.FlagSet) {
+		fset.StringVar(&name, "name", "world", "who to greet")
+	}, func(a []string) error {
+		args = a
+		return nil
+	}))
+	c.Register(&ctxFuncCmd{run: func(ctx context.Context, a []string) error {
+		verbose = sub.GlobalFlags(ctx).Lookup("global").Value.String() == "set"
+		return nil
+	}})
+
+	p, err := c.ParseCommand([]string{"subtest", "-global", "set", "greet", "-name", "gopher", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Command.Name() != "greet" {
+		t.Errorf("Expected:\t%q", "greet")
+		t.Errorf("Got:\t\t%q", p.Command.Name())
+	}
+	if want := map[string]string{"global": "set"}; !reflect.DeepEqual(p.GlobalFlags, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", p.GlobalFlags)
+	}
+	if want := map[string]string{"name": "gopher"}; !reflect.DeepEqual(p.Flags, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", p.Flags)
+	}
+
+	for i := 0; i < 2; i++ {
+		err = c.Run([]string{"subtest", "greet"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (global != "default") || (name != "world") {
+			t.Fatalf("Run didn't reset flags: %q %q", global, name)
+		}
+
+		err = c.Execute(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (global != "set") || (name != "gopher") {
+			t.Errorf("Execute didn't restore flags: %q %q", global, name)
+		}
+		if !reflect.DeepEqual(args, []string{"a", "b"}) {
+			t.Errorf("Expected:\t%q", []string{"a", "b"})
+			t.Errorf("Got:\t\t%q", args)
+		}
+	}
+
+	p, err = c.ParseCommand([]string{"subtest", "-global", "set", "ctx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Execute(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verbose {
+		t.Errorf("Global flags not passed in context")
+	}
+}
+
+func TestParseCommandError(t *testing.T) {
+	c := &sub.Commander{Silent: true}
+
+	p, err := c.ParseCommand([]string{"subtest", "missing"})
+	if (p != nil) || (err == nil) {
+		t.Errorf("Got:\t%v, %v", p, err)
+	}
+}
+
+type ctxFuncCmd struct {
+	run func(context.Context, []string) error
+}
+
+func (cmd *ctxFuncCmd) Name() string {
+	return "ctx"
+}
+
+func (cmd *ctxFuncCmd) Desc() string {
+	return ""
+}
+
+func (cmd *ctxFuncCmd) Help() string {
+	return ""
+}
+
+func (cmd *ctxFuncCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *ctxFuncCmd) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+func (cmd *ctxFuncCmd) RunContext(ctx context.Context, args []string) error {
+	return cmd.run(ctx, args)
+}
@@ -0,0 +1,176 @@
+package sub
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// osExit is called by RunOS to exit the process. It is a variable so
+// that tests can replace it.
+var osExit = os.Exit
+
+// ExitError is an error that carries an exit code. Commands can return
+// an *ExitError to signal that the program should exit with a specific
+// status.
+type ExitError struct {
+	// Code is the exit code.
+	Code int
+
+	// Err is the underlying error. It may be nil.
+	Err error
+}
+
+func (err *ExitError) Error() string {
+	if err.Err == nil {
+		return fmt.Sprintf("exit status %v", err.Code)
+	}
+
+	return err.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (err *ExitError) Unwrap() error {
+	return err.Err
+}
+
+// RunOS runs c using the process's command-line arguments, with the
+// first argument trimmed to its base name, and then exits the process
+// if there was an error.
+//
+// If Run returns flag.ErrHelp or ErrVersion, the process exits with
+// code 0. If it returns an *ExitError, the process exits with that
+// error's code, printing the underlying error first if there is one.
+// Any other non-nil error is printed, unless Run has already reported
+// it, and the process exits with code 1. If c has an ErrorHandler, it
+// is left to print the error instead. If Run returns nil, RunOS
+// returns normally.
+func (c *Commander) RunOS() {
+	err := c.Run(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...))
+	if err == nil {
+		return
+	}
+
+	if (err == flag.ErrHelp) || (err == ErrVersion) {
+		osExit(0)
+		return
+	}
+
+	if (c.ErrorHandler == nil) && !c.reported(err) {
+		c.PrintError(err)
+	}
+	osExit(ExitCode(err))
+}
+
+// DefaultErrorHandler writes err to w with the default error prefix.
+// If err is an *ExitError, only its underlying error is written, and
+// nothing is written if it doesn't have one. Commander.PrintError
+// provides more detailed messages for some errors.
+func DefaultErrorHandler(w io.Writer, err error) {
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		if exit.Err == nil {
+			return
+		}
+		err = exit.Err
+	}
+
+	fmt.Fprintf(w, "Error: %v\n", err)
+}
+
+// PrintError writes err to c's error output in the format used by
+// RunOS, prefixed with c's ErrorPrefix. Nothing is written if err is
+// nil, flag.ErrHelp, or ErrVersion.
+//
+// An *ExitError is written as its underlying error, with nothing
+// written if it doesn't have one. An *UnknownCommandError is written
+// along with suggestions for what the user may have meant, an
+// *AmbiguousCommandError along with the commands that it matched, and
+// a *FlagParseError is followed by a pointer to the help for the
+// command whose flags were invalid. Any other error is written as is.
+//
+// Clients that wrap Run can use PrintError to report errors in the
+// same way that RunOS does.
+func (c *Commander) PrintError(err error) {
+	if (err == nil) || (err == flag.ErrHelp) || (err == ErrVersion) {
+		return
+	}
+
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		if exit.Err == nil {
+			return
+		}
+		err = exit.Err
+	}
+
+	w := c.errOutput()
+
+	var unknown *UnknownCommandError
+	if errors.As(err, &unknown) {
+		c.printUnknown(w, unknown.Name)
+		return
+	}
+
+	var ambiguous *AmbiguousCommandError
+	if errors.As(err, &ambiguous) {
+		c.printAmbiguous(w, ambiguous.Name, ambiguous.Matches)
+		return
+	}
+
+	var parse *FlagParseError
+	if errors.As(err, &parse) {
+		fmt.Fprintf(w, "%v%v\n", c.errorPrefix(), err)
+		if parse.Cmd == "" {
+			fmt.Fprintf(w, "Run '%v help' for usage.\n", c.progName())
+			return
+		}
+		fmt.Fprintf(w, "Run '%v help %v' for usage.\n", c.progName(), parse.Cmd)
+		return
+	}
+
+	fmt.Fprintf(w, "%v%v\n", c.errorPrefix(), err)
+}
+
+// errorPrefix returns the prefix for error messages.
+func (c *Commander) errorPrefix() string {
+	if c.ErrorPrefix != "" {
+		return c.ErrorPrefix
+	}
+	if c.parent != nil {
+		return c.parent.errorPrefix()
+	}
+	return "Error: "
+}
+
+// reported returns true if err is an error that Parse already printed
+// a message about.
+func (c *Commander) reported(err error) bool {
+	if c.silent() || c.helpDisabled() {
+		return false
+	}
+
+	var unknown *UnknownCommandError
+	var ambiguous *AmbiguousCommandError
+	var parse *FlagParseError
+	return errors.As(err, &unknown) || errors.As(err, &ambiguous) || errors.As(err, &parse)
+}
+
+// ExitCode returns the exit code that a program should exit with after
+// getting err from Run. It returns 0 if err is nil, the code of the
+// first *ExitError in err's chain if there is one, and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return exit.Code
+	}
+
+	return 1
+}
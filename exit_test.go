@@ -0,0 +1,140 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestRunOS(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		out  string
+	}{
+		{name: "Success", code: -1},
+		{name: "Help", err: flag.ErrHelp, code: 0},
+		{name: "Exit Error", err: &sub.ExitError{Code: 3, Err: errors.New("bad usage")}, code: 3, out: "Error: bad usage\n"},
+		{name: "Silent Exit Error", err: &sub.ExitError{Code: 4}, code: 4},
+		{name: "Other Error", err: errors.New("failed"), code: 1, out: "Error: failed\n"},
+	}
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := -1
+			restore := sub.SetOSExit(func(c int) { code = c })
+			defer restore()
+
+			os.Args = []string{"/path/to/subtest", "run"}
+
+			var out bytes.Buffer
+			c := &sub.Commander{Output: &out}
+			c.Register(sub.Func("run", "", "", nil, func([]string) error {
+				return test.err
+			}))
+			c.RunOS()
+
+			if code != test.code {
+				t.Errorf("Expected:\t%v", test.code)
+				t.Errorf("Got:\t\t%v", code)
+			}
+			if out.String() != test.out {
+				t.Errorf("Expected:\t%q", test.out)
+				t.Errorf("Got:\t\t%q", out.String())
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "Nil", code: 0},
+		{name: "Exit Error", err: &sub.ExitError{Code: 2, Err: errors.New("usage error")}, code: 2},
+		{name: "Exit Error Without Err", err: &sub.ExitError{Code: 5}, code: 5},
+		{name: "Wrapped Exit Error", err: fmt.Errorf("run: %w", &sub.ExitError{Code: 3}), code: 3},
+		{name: "Other Error", err: errors.New("failed"), code: 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			code := sub.ExitCode(test.err)
+			if code != test.code {
+				t.Errorf("Expected:\t%v", test.code)
+				t.Errorf("Got:\t\t%v", code)
+			}
+		})
+	}
+}
+
+func TestExitErrorAs(t *testing.T) {
+	inner := errors.New("usage error")
+	err := fmt.Errorf("run: %w", &sub.ExitError{Code: 2, Err: inner})
+
+	var exit *sub.ExitError
+	if !errors.As(err, &exit) {
+		t.Fatalf("errors.As failed on %v", err)
+	}
+	if exit.Code != 2 {
+		t.Errorf("Expected:\t%v", 2)
+		t.Errorf("Got:\t\t%v", exit.Code)
+	}
+	if !errors.Is(err, inner) {
+		t.Errorf("errors.Is failed on %v", err)
+	}
+}
+
+func TestRunOSParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		silent bool
+		out    string
+	}{
+		{name: "Unknown Command", args: []string{"missing"}, out: "Error: No such command: \"missing\"\n\nUsage: subtest <subcommand> [subcommand arguments]\n\nCommands:\n\trun  \n"},
+		{name: "Silent Unknown Command", args: []string{"missing"}, silent: true, out: "Error: No such command: \"missing\"\n"},
+		{name: "Silent Flag Parse Error", args: []string{"run", "-unknown"}, silent: true, out: "Error: invalid flags for run: flag provided but not defined: -unknown\nRun 'subtest help run' for usage.\n"},
+	}
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := -1
+			restore := sub.SetOSExit(func(c int) { code = c })
+			defer restore()
+
+			os.Args = append([]string{"/path/to/subtest"}, test.args...)
+
+			var out bytes.Buffer
+			c := &sub.Commander{Output: &out, Silent: test.silent}
+			c.Register(sub.Func("run", "", "", nil, func([]string) error { return nil }))
+			c.RunOS()
+
+			if code != 1 {
+				t.Errorf("Expected:\t%v", 1)
+				t.Errorf("Got:\t\t%v", code)
+			}
+			if out.String() != test.out {
+				t.Errorf("Expected:\t%q", test.out)
+				t.Errorf("Got:\t\t%q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package sub
+
+import "io"
+
+// SetOSExit replaces the function used to exit the process
+// and returns a function that restores the original.
+func SetOSExit(exit func(int)) (restore func()) {
+	prev := osExit
+	osExit = exit
+	return func() { osExit = prev }
+}
+
+// SetIsTerminal replaces the function used to detect whether output
+// is a terminal and returns a function that restores the original.
+func SetIsTerminal(f func(io.Writer) bool) (restore func()) {
+	prev := isTerminal
+	isTerminal = f
+	return func() { isTerminal = prev }
+}
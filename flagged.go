@@ -0,0 +1,50 @@
+package sub
+
+import (
+	"context"
+	"flag"
+)
+
+// FlaggedCommand is a Command that needs access to its parsed flags,
+// such as to find out which of them were explicitly set using
+// flag.FlagSet.Visit. If a command implements FlaggedCommand and not
+// CommandContext, RunWithFlags is called instead of Run.
+type FlaggedCommand interface {
+	Command
+
+	// RunWithFlags is like Run, but is also passed the FlagSet that the
+	// command's flags were parsed with. If the command is dispatched
+	// without having been parsed by Run, the FlagSet contains the
+	// command's flags at their default values.
+	RunWithFlags(fset *flag.FlagSet, args []string) error
+}
+
+// flagSetKey is the context key under which RunContext stores the
+// parsed FlagSet of the command being run.
+type flagSetKey struct{}
+
+// flagSetFor returns the parsed FlagSet of cmd from ctx, or an
+// unparsed one if there isn't one.
+func flagSetFor(ctx context.Context, cmd Command) *flag.FlagSet {
+	if fset, ok := ctx.Value(flagSetKey{}).(*flag.FlagSet); ok && (fset.Name() == cmd.Name()) {
+		return fset
+	}
+
+	fset := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	cmd.Flags(fset)
+	_ = fset.Parse(nil)
+	return fset
+}
+
+// runCommand runs cmd with whichever of its run methods is most
+// specific.
+func runCommand(ctx context.Context, cmd Command, args []string) error {
+	switch cmd := cmd.(type) {
+	case CommandContext:
+		return cmd.RunContext(ctx, args)
+	case FlaggedCommand:
+		return cmd.RunWithFlags(flagSetFor(ctx, cmd), args)
+	default:
+		return cmd.Run(args)
+	}
+}
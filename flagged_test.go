@@ -0,0 +1,97 @@
+package sub_test
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type flaggedCmd struct {
+	a, b string
+	set  []string
+	args []string
+}
+
+func (cmd *flaggedCmd) Name() string {
+	return "flagged"
+}
+
+func (cmd *flaggedCmd) Desc() string {
+	return "see which flags were set"
+}
+
+func (cmd *flaggedCmd) Help() string {
+	return "Usage: flagged [options]"
+}
+
+func (cmd *flaggedCmd) Flags(fset *flag.FlagSet) {
+	fset.StringVar(&cmd.a, "a", "", "the a flag")
+	fset.StringVar(&cmd.b, "b", "", "the b flag")
+}
+
+func (cmd *flaggedCmd) Run(args []string) error {
+	panic("Run called instead of RunWithFlags")
+}
+
+func (cmd *flaggedCmd) RunWithFlags(fset *flag.FlagSet, args []string) error {
+	cmd.set = nil
+	fset.Visit(func(f *flag.Flag) {
+		cmd.set = append(cmd.set, f.Name)
+	})
+	cmd.args = args
+	return nil
+}
+
+func TestFlaggedCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(sub.Command) sub.Command
+		args []string
+		set  []string
+	}{
+		{name: "None", wrap: func(cmd sub.Command) sub.Command { return cmd }},
+		{name: "One", wrap: func(cmd sub.Command) sub.Command { return cmd }, args: []string{"-b", "x"}, set: []string{"b"}},
+		{name: "Both", wrap: func(cmd sub.Command) sub.Command { return cmd }, args: []string{"-a", "x", "-b", "y"}, set: []string{"a", "b"}},
+		{name: "Wrapped", wrap: sub.Hidden, args: []string{"-a", "x"}, set: []string{"a"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &flaggedCmd{}
+			var c sub.Commander
+			c.Register(test.wrap(cmd))
+
+			err := c.Run(append(append([]string{"subtest", "flagged"}, test.args...), "arg"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cmd.set, test.set) {
+				t.Errorf("Expected:\t%q", test.set)
+				t.Errorf("Got:\t\t%q", cmd.set)
+			}
+			if !reflect.DeepEqual(cmd.args, []string{"arg"}) {
+				t.Errorf("Expected:\t%q", []string{"arg"})
+				t.Errorf("Got:\t\t%q", cmd.args)
+			}
+		})
+	}
+}
+
+func TestFlaggedCommandDispatch(t *testing.T) {
+	cmd := &flaggedCmd{}
+	var c sub.Commander
+	c.Register(cmd)
+
+	err := c.Dispatch(cmd, []string{"arg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmd.set) != 0 {
+		t.Errorf("Flags set without parsing: %q", cmd.set)
+	}
+}
@@ -0,0 +1,70 @@
+package sub
+
+import (
+	"flag"
+	"strings"
+)
+
+type funcCmd struct {
+	name     string
+	desc     string
+	help     string
+	flags    func(*flag.FlagSet)
+	run      func([]string) error
+	examples string
+}
+
+// Func returns a Command that uses the given values for its Name,
+// Desc, and Help methods, calls flags from its Flags method, and calls
+// run from its Run method. flags may be nil if the command has no
+// flags. If any examples are given, they are joined by blank lines and
+// returned from the command's Example method.
+func Func(name, desc, help string, flags func(*flag.FlagSet), run func([]string) error, examples ...string) Command {
+	return funcCmd{
+		name:     name,
+		desc:     desc,
+		help:     help,
+		flags:    flags,
+		run:      run,
+		examples: strings.Join(examples, "\n\n"),
+	}
+}
+
+// FuncE is like Func, but run also returns an exit code. If the exit
+// code is non-zero, the command's Run method returns an *ExitError
+// containing both the code and the error returned by run.
+func FuncE(name, desc, help string, flags func(*flag.FlagSet), run func([]string) (int, error), examples ...string) Command {
+	return Func(name, desc, help, flags, func(args []string) error {
+		code, err := run(args)
+		if code != 0 {
+			return &ExitError{Code: code, Err: err}
+		}
+		return err
+	}, examples...)
+}
+
+func (cmd funcCmd) Name() string {
+	return cmd.name
+}
+
+func (cmd funcCmd) Desc() string {
+	return cmd.desc
+}
+
+func (cmd funcCmd) Help() string {
+	return cmd.help
+}
+
+func (cmd funcCmd) Example() string {
+	return cmd.examples
+}
+
+func (cmd funcCmd) Flags(fset *flag.FlagSet) {
+	if cmd.flags != nil {
+		cmd.flags(fset)
+	}
+}
+
+func (cmd funcCmd) Run(args []string) error {
+	return cmd.run(args)
+}
@@ -0,0 +1,156 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestFunc(t *testing.T) {
+	var got []string
+	var flagVal string
+
+	var c sub.Commander
+	c.Register(sub.Func(
+		"func",
+		"a function command",
+		"Usage: func [options] [args...]",
+		func(fset *flag.FlagSet) {
+			fset.StringVar(&flagVal, "flag", "", "a flag")
+		},
+		func(args []string) error {
+			got = args
+			return nil
+		},
+	))
+	c.Register(sub.Func("noflags", "no flags", "", nil, func(args []string) error {
+		return nil
+	}))
+
+	err := c.Run([]string{"subtest", "func", "-flag", "value", "one", "two"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if flagVal != "value" {
+		t.Errorf("Expected:\t%q", "value")
+		t.Errorf("Got:\t\t%q", flagVal)
+	}
+	if want := []string{"one", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+
+	err = c.Run([]string{"subtest", "noflags"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestFuncE(t *testing.T) {
+	fail := errors.New("failed")
+
+	tests := []struct {
+		name string
+		code int
+		err  error
+		ret  error
+	}{
+		{name: "Success"},
+		{name: "Error Without Code", err: fail, ret: fail},
+		{name: "Code", code: 3, err: fail, ret: &sub.ExitError{Code: 3, Err: fail}},
+		{name: "Code Without Error", code: 2, ret: &sub.ExitError{Code: 2}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := sub.FuncE("exit", "", "", nil, func([]string) (int, error) {
+				return test.code, test.err
+			})
+
+			err := cmd.Run(nil)
+			if !reflect.DeepEqual(err, test.ret) {
+				t.Errorf("Expected:\t%v", test.ret)
+				t.Errorf("Got:\t\t%v", err)
+			}
+		})
+	}
+}
+
+func TestFuncExamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		examples []string
+		cout     string
+	}{
+		{
+			name:     "With Examples",
+			examples: []string{"greet world", "greet -loud world\ngreet -loud again"},
+			cout: `Usage: greet [options] <name>
+
+Options:
+  -loud
+    	shout the greeting
+
+Examples:
+  greet world
+
+  greet -loud world
+  greet -loud again
+`,
+		},
+		{
+			name: "Without Examples",
+			cout: `Usage: greet [options] <name>
+
+Options:
+  -loud
+    	shout the greeting
+`,
+		},
+		{
+			name:     "Empty Example",
+			examples: []string{""},
+			cout: `Usage: greet [options] <name>
+
+Options:
+  -loud
+    	shout the greeting
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout}
+			c.Register(sub.Func(
+				"greet",
+				"greet someone",
+				"Usage: greet [options] <name>",
+				func(fset *flag.FlagSet) { fset.Bool("loud", false, "shout the greeting") },
+				nil,
+				test.examples...,
+			))
+
+			err := c.HelpCmd().Run([]string{"greet"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
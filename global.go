@@ -0,0 +1,51 @@
+package sub
+
+import (
+	"context"
+	"flag"
+)
+
+// contextKey is the type of the context keys defined by this package.
+type contextKey struct {
+	name string
+}
+
+func (k *contextKey) String() string {
+	return "sub context key " + k.name
+}
+
+// GlobalFlagsKey is the context key under which RunContext stores the
+// Commander's parsed global FlagSet, a *flag.FlagSet, in the context
+// that it passes to commands that implement CommandContext. For a
+// command of a nested Commander, it is the nested Commander's global
+// FlagSet.
+//
+// This is the recommended way for a command to read a global flag,
+// such as a -verbose flag, without capturing its variable in a
+// closure:
+//
+//    func (cmd *listCmd) RunContext(ctx context.Context, args []string) error {
+//    	global := sub.GlobalFlags(ctx)
+//    	verbose := global.Lookup("verbose").Value.(flag.Getter).Get().(bool)
+//    	...
+//    }
+var GlobalFlagsKey = &contextKey{"global-flags"}
+
+// GlobalFlagSet returns the global FlagSet that was created and
+// parsed by the most recent call to Run, or nil if Run hasn't been
+// called yet. It can be used to check which global flags were set,
+// such as with the FlagSet's Lookup or Visit methods. It is safe to
+// call concurrently with Run, but if Run is being called concurrently
+// then which call's FlagSet it returns is unspecified, so the FlagSet
+// stored in the context under GlobalFlagsKey should be used instead.
+func (c *Commander) GlobalFlagSet() *flag.FlagSet {
+	fset, _ := c.global.Load().(*flag.FlagSet)
+	return fset
+}
+
+// GlobalFlags returns the global FlagSet stored in ctx under
+// GlobalFlagsKey. It returns nil if there isn't one.
+func GlobalFlags(ctx context.Context) *flag.FlagSet {
+	fset, _ := ctx.Value(GlobalFlagsKey).(*flag.FlagSet)
+	return fset
+}
@@ -0,0 +1,136 @@
+package sub_test
+
+import (
+	"context"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type globalCmd struct {
+	verbose bool
+	found   bool
+}
+
+func (cmd *globalCmd) Name() string {
+	return "global"
+}
+
+func (cmd *globalCmd) Desc() string {
+	return "read a global flag"
+}
+
+func (cmd *globalCmd) Help() string {
+	return "Usage: global"
+}
+
+func (cmd *globalCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *globalCmd) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+func (cmd *globalCmd) RunContext(ctx context.Context, args []string) error {
+	global, ok := ctx.Value(sub.GlobalFlagsKey).(*flag.FlagSet)
+	if !ok {
+		return nil
+	}
+	if global != sub.GlobalFlags(ctx) {
+		panic("GlobalFlags returned a different FlagSet")
+	}
+
+	cmd.found = true
+	cmd.verbose = global.Lookup("verbose").Value.(flag.Getter).Get().(bool)
+	return nil
+}
+
+func TestGlobalFlagsContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		verbose bool
+	}{
+		{name: "Unset", args: []string{"subtest", "global"}},
+		{name: "Set", args: []string{"subtest", "-verbose", "global"}, verbose: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &globalCmd{}
+			c := sub.NewCommander(sub.WithFlags(func(fset *flag.FlagSet) {
+				fset.Bool("verbose", false, "verbose output")
+			}))
+			c.Register(cmd)
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmd.found {
+				t.Fatal("Global FlagSet not found in context")
+			}
+			if cmd.verbose != test.verbose {
+				t.Errorf("Expected:\t%v", test.verbose)
+				t.Errorf("Got:\t\t%v", cmd.verbose)
+			}
+
+			global := c.GlobalFlagSet()
+			if global == nil {
+				t.Fatal("GlobalFlagSet returned nil")
+			}
+			if v := global.Lookup("verbose").Value.String(); v != "true" && test.verbose {
+				t.Errorf("Expected:\t%v", "true")
+				t.Errorf("Got:\t\t%v", v)
+			}
+		})
+	}
+}
+
+func TestGlobalFlagsMissing(t *testing.T) {
+	if fset := sub.GlobalFlags(context.Background()); fset != nil {
+		t.Errorf("Got:\t%v", fset)
+	}
+
+	var c sub.Commander
+	if fset := c.GlobalFlagSet(); fset != nil {
+		t.Errorf("Got:\t%v", fset)
+	}
+}
+
+func TestGlobalFlagSetConcurrent(t *testing.T) {
+	c := sub.NewCommander(sub.WithFlags(func(fset *flag.FlagSet) {
+		fset.Bool("verbose", false, "verbose output")
+	}))
+	c.Register(sub.Func("noop", "", "", nil, func([]string) error { return nil }))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = c.GlobalFlagSet()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		err := c.Run([]string{"subtest", "-verbose", "noop"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+
+	var set []string
+	c.GlobalFlagSet().Visit(func(f *flag.Flag) {
+		set = append(set, f.Name)
+	})
+	if !reflect.DeepEqual(set, []string{"verbose"}) {
+		t.Errorf("Expected:\t%q", []string{"verbose"})
+		t.Errorf("Got:\t\t%q", set)
+	}
+}
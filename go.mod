@@ -1,3 +1,3 @@
 module github.com/DeedleFake/sub
 
-go 1.12
+go 1.14
@@ -0,0 +1,146 @@
+package sub
+
+import (
+	"flag"
+	"strings"
+	"time"
+)
+
+// HelpData is a structured description of a Commander and its
+// commands. It contains the same information as the help output, but
+// can be marshaled to JSON, used in templates, or fed to documentation
+// generators instead.
+type HelpData struct {
+	// Name is the name of the program.
+	Name string `json:"name"`
+
+	// Usage is the usage line of the program.
+	Usage string `json:"usage"`
+
+	// Help and LongHelp are the Commander's Help and LongHelp fields
+	// with surrounding whitespace removed.
+	Help     string `json:"help,omitempty"`
+	LongHelp string `json:"longHelp,omitempty"`
+
+	// GlobalFlags describes the global flags.
+	GlobalFlags []FlagData `json:"globalFlags,omitempty"`
+
+	// Commands describes the commands.
+	Commands []CommandData `json:"commands"`
+}
+
+// CommandData describes a single command for a HelpData.
+type CommandData struct {
+	Name       string     `json:"name"`
+	Desc       string     `json:"desc"`
+	Help       string     `json:"help,omitempty"`
+	Flags      []FlagData `json:"flags,omitempty"`
+	Group      string     `json:"group,omitempty"`
+	Hidden     bool       `json:"hidden,omitempty"`
+	Deprecated string     `json:"deprecated,omitempty"`
+
+	cmd Command
+}
+
+// FlagData describes a single flag for a HelpData. Type is one of
+// bool, string, int, int64, uint, uint64, float, or duration, the
+// same types used by PluginFlagsFlag, or value if the flag is of some
+// other type.
+type FlagData struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+}
+
+// HelpData returns a description of c and of the commands that its
+// help summary lists, in the same order. Unlike the help summary, it
+// includes hidden commands, leaving it up to the client to decide
+// whether to show them. The flags of each command include any
+// persistent flags.
+func (c *Commander) HelpData() HelpData {
+	return (&helpCmd{Commander: c, all: true}).helpData(true)
+}
+
+// helpData returns the data shown by the help command. The help
+// summary doesn't need the help and flags of every command, and
+// getting them would initialize Lazy commands, so they are only filled
+// in if full is true.
+func (h *helpCmd) helpData(full bool) HelpData {
+	data := HelpData{
+		Name:     h.progName(),
+		Usage:    h.usage(),
+		Help:     strings.TrimSpace(h.Commander.Help),
+		LongHelp: h.longHelp(),
+	}
+	if h.hasGlobalFlags() {
+		fset := flag.NewFlagSet(h.progName(), flag.ContinueOnError)
+		h.globalFlags(fset)
+		data.GlobalFlags = flagData(fset)
+	}
+
+	for _, cmd := range h.listed() {
+		cd := CommandData{
+			Name:       h.transformName(cmd.Name()),
+			Desc:       cmd.Desc(),
+			Group:      groupOf(cmd),
+			Hidden:     isHidden(cmd),
+			Deprecated: deprecation(cmd),
+
+			cmd: cmd,
+		}
+		if full {
+			fset := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+			h.cmdFlags(cmd, fset)
+			cd.Help = strings.TrimSpace(cmd.Help())
+			cd.Flags = flagData(fset)
+		}
+		data.Commands = append(data.Commands, cd)
+	}
+
+	return data
+}
+
+// flagData returns descriptions of the flags defined in fset, sorted
+// by name.
+func flagData(fset *flag.FlagSet) []FlagData {
+	var flags []FlagData
+	fset.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, FlagData{
+			Name:    f.Name,
+			Type:    flagType(f),
+			Default: f.DefValue,
+			Usage:   f.Usage,
+		})
+	})
+	return flags
+}
+
+// flagType returns the name of the type of f's value.
+func flagType(f *flag.Flag) string {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return "value"
+	}
+
+	switch getter.Get().(type) {
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case int:
+		return "int"
+	case int64:
+		return "int64"
+	case uint:
+		return "uint"
+	case uint64:
+		return "uint64"
+	case float64:
+		return "float"
+	case time.Duration:
+		return "duration"
+	default:
+		return "value"
+	}
+}
@@ -0,0 +1,84 @@
+package sub_test
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestHelpData(t *testing.T) {
+	c := newTemplateCommander("")
+	c.PersistentFlags = func(fset *flag.FlagSet) {
+		fset.Duration("timeout", time.Second, "how long to wait")
+	}
+
+	data := c.HelpData()
+	if data.Name != "subtest" {
+		t.Errorf("Expected:\t%q", "subtest")
+		t.Errorf("Got:\t\t%q", data.Name)
+	}
+	if want := "Usage: subtest [global options] <subcommand> [subcommand arguments]"; data.Usage != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", data.Usage)
+	}
+	if data.Help != "Some help." {
+		t.Errorf("Expected:\t%q", "Some help.")
+		t.Errorf("Got:\t\t%q", data.Help)
+	}
+	if want := []sub.FlagData{{Name: "v", Type: "bool", Default: "false", Usage: "verbose output"}}; !reflect.DeepEqual(data.GlobalFlags, want) {
+		t.Errorf("Expected:\t%+v", want)
+		t.Errorf("Got:\t\t%+v", data.GlobalFlags)
+	}
+
+	var names []string
+	cmds := make(map[string]sub.CommandData)
+	for _, cmd := range data.Commands {
+		names = append(names, cmd.Name)
+		cmds[cmd.Name] = cmd
+	}
+	if want := []string{"build", "help", "old", "rm", "secret", "test"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+
+	if cmd := cmds["build"]; (cmd.Desc != "build things") || (cmd.Group != "Development") {
+		t.Errorf("Unexpected build command: %+v", cmd)
+	}
+	if !cmds["secret"].Hidden {
+		t.Errorf("secret is not hidden")
+	}
+	if cmds["old"].Deprecated != "don't" {
+		t.Errorf("Expected:\t%q", "don't")
+		t.Errorf("Got:\t\t%q", cmds["old"].Deprecated)
+	}
+
+	test := cmds["test"]
+	if test.Help != "This is just a simple test.\nNo, really. That's it.\nProbably." {
+		t.Errorf("Unexpected help: %q", test.Help)
+	}
+	want := []sub.FlagData{
+		{Name: "flag", Type: "string", Default: "test", Usage: "a flag test"},
+		{Name: "timeout", Type: "duration", Default: "1s", Usage: "how long to wait"},
+	}
+	if !reflect.DeepEqual(test.Flags, want) {
+		t.Errorf("Expected:\t%+v", want)
+		t.Errorf("Got:\t\t%+v", test.Flags)
+	}
+
+	buf, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	var decoded map[string]interface{}
+	err = json.Unmarshal(buf, &decoded)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if decoded["name"] != "subtest" {
+		t.Errorf("Unexpected JSON: %s", buf)
+	}
+}
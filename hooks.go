@@ -0,0 +1,59 @@
+package sub
+
+// PreRunner is a Command with a hook that is called before it is run.
+type PreRunner interface {
+	Command
+
+	// PreRun is called after the command's flags have been parsed but
+	// before Run is called. If it returns an error, Run is not called.
+	PreRun(args []string) error
+}
+
+// PostRunner is a Command with a hook that is called after it is run.
+type PostRunner interface {
+	Command
+
+	// PostRun is called after Run, provided that PreRun didn't fail. It
+	// is passed the error returned by Run, which may be nil, and its
+	// own return value is used in place of that error.
+	PostRun(args []string, err error) error
+}
+
+// runHooks runs cmd using run, surrounded by the Commander's hooks and
+// the command's own hooks. The Commander's PreRun is called first and
+// its PostRun is called last.
+func (c *Commander) runHooks(cmd Command, args []string, run RunFunc) error {
+	if c.PreRun != nil {
+		err := c.PreRun(cmd, args)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := runCmdHooks(cmd, args, run)
+
+	if c.PostRun != nil {
+		err = c.PostRun(cmd, args, err)
+	}
+
+	return err
+}
+
+// runCmdHooks runs cmd using run, surrounded by the command's own
+// hooks.
+func runCmdHooks(cmd Command, args []string, run RunFunc) error {
+	if pre, ok := cmd.(PreRunner); ok {
+		err := pre.PreRun(args)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := run(cmd, args)
+
+	if post, ok := cmd.(PostRunner); ok {
+		err = post.PostRun(args, err)
+	}
+
+	return err
+}
@@ -0,0 +1,145 @@
+package sub_test
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestRunHooks(t *testing.T) {
+	runErr := errors.New("run failed")
+	preErr := errors.New("pre-run failed")
+
+	tests := []struct {
+		name   string
+		preErr error
+		runErr error
+		calls  []string
+		ret    error
+	}{
+		{
+			name:  "Success",
+			calls: []string{"pre", "run", "post <nil>"},
+		},
+		{
+			name:   "Run Error",
+			runErr: runErr,
+			calls:  []string{"pre", "run", "post run failed"},
+			ret:    runErr,
+		},
+		{
+			name:   "PreRun Error",
+			preErr: preErr,
+			calls:  []string{"pre"},
+			ret:    preErr,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls []string
+
+			c := &sub.Commander{
+				PreRun: func(cmd sub.Command, args []string) error {
+					calls = append(calls, "pre")
+					return test.preErr
+				},
+				PostRun: func(cmd sub.Command, args []string, err error) error {
+					msg := "<nil>"
+					if err != nil {
+						msg = err.Error()
+					}
+					calls = append(calls, "post "+msg)
+					return err
+				},
+			}
+			c.Register(sub.Func("run", "", "", nil, func([]string) error {
+				calls = append(calls, "run")
+				return test.runErr
+			}))
+
+			err := c.Run([]string{"subtest", "run"})
+			if err != test.ret {
+				t.Errorf("Expected:\t%v", test.ret)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if !reflect.DeepEqual(calls, test.calls) {
+				t.Errorf("Expected:\t%q", test.calls)
+				t.Errorf("Got:\t\t%q", calls)
+			}
+		})
+	}
+}
+
+type hookedCmd struct {
+	calls *[]string
+}
+
+func (cmd *hookedCmd) Name() string {
+	return "hooked"
+}
+
+func (cmd *hookedCmd) Desc() string {
+	return ""
+}
+
+func (cmd *hookedCmd) Help() string {
+	return ""
+}
+
+func (cmd *hookedCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *hookedCmd) PreRun(args []string) error {
+	*cmd.calls = append(*cmd.calls, "command pre")
+	return nil
+}
+
+func (cmd *hookedCmd) Run(args []string) error {
+	*cmd.calls = append(*cmd.calls, "run")
+	return nil
+}
+
+func (cmd *hookedCmd) PostRun(args []string, err error) error {
+	*cmd.calls = append(*cmd.calls, "command post")
+	return err
+}
+
+func TestCommandHooks(t *testing.T) {
+	var calls []string
+
+	c := &sub.Commander{
+		PreRun: func(cmd sub.Command, args []string) error {
+			calls = append(calls, "commander pre")
+			return nil
+		},
+		PostRun: func(cmd sub.Command, args []string, err error) error {
+			calls = append(calls, "commander post")
+			return err
+		},
+	}
+	c.Register(sub.WithGroup(&hookedCmd{calls: &calls}, "Hooked"))
+
+	err := c.Run([]string{"subtest", "hooked"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"commander pre",
+		"command pre",
+		"run",
+		"command post",
+		"commander post",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", calls)
+	}
+}
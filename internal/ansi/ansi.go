@@ -0,0 +1,23 @@
+// Package ansi contains ANSI escape sequences for styling terminal
+// output.
+package ansi
+
+const (
+	// Reset clears all styling.
+	Reset = "\x1b[0m"
+
+	// Bold makes text bold.
+	Bold = "\x1b[1m"
+
+	// Dim makes text faint.
+	Dim = "\x1b[2m"
+
+	// Underline underlines text.
+	Underline = "\x1b[4m"
+)
+
+// Link returns text as an OSC 8 hyperlink to uri. Terminals that do
+// not support hyperlinks display text as is.
+func Link(uri, text string) string {
+	return "\x1b]8;;" + uri + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
@@ -0,0 +1,125 @@
+package sub
+
+import (
+	"io"
+	"os"
+)
+
+// IO is a bundle of the streams that a command can use.
+type IO struct {
+	// In is the reader that the command should read input from.
+	In io.Reader
+
+	// Out is the writer that the command should write its normal
+	// output to.
+	Out io.Writer
+
+	// Err is the writer that the command should write errors and
+	// diagnostics to.
+	Err io.Writer
+}
+
+// StandardIO returns an IO that uses the process's standard streams.
+func StandardIO() IO {
+	return IO{
+		In:  os.Stdin,
+		Out: os.Stdout,
+		Err: os.Stderr,
+	}
+}
+
+// InputCommand is a Command that reads input. Before an InputCommand
+// is run, its SetInput method is called with the Commander's input,
+// which is IO.In if it is set, then Input, falling back to os.Stdin.
+type InputCommand interface {
+	Command
+
+	// SetInput sets the reader that the command should read its input
+	// from.
+	SetInput(r io.Reader)
+}
+
+// IOCommand is a Command that uses a full set of streams. Before an
+// IOCommand is run, its SetIO method is called with the Commander's
+// streams. Any fields of the Commander's IO that are not set are
+// filled in from its other fields, its parent, or the process's
+// standard streams, so none of them are ever nil.
+type IOCommand interface {
+	Command
+
+	// SetIO sets the streams that the command should use.
+	SetIO(io IO)
+}
+
+// ioCommandOf returns cmd as an IOCommand, looking through the
+// package's wrappers, which implement IOCommand whether or not the
+// commands that they wrap do, or nil if it isn't one.
+func ioCommandOf(cmd Command) IOCommand {
+	if w, ok := cmd.(interface{ ioCommand() IOCommand }); ok {
+		return w.ioCommand()
+	}
+	if ioCmd, ok := cmd.(IOCommand); ok {
+		return ioCmd
+	}
+	return nil
+}
+
+func (c *Commander) stdout() io.Writer {
+	if c.IO.Out == nil {
+		if c.parent != nil {
+			return c.parent.stdout()
+		}
+		return os.Stdout
+	}
+
+	return c.IO.Out
+}
+
+// io returns the fully resolved IO of c.
+func (c *Commander) io() IO {
+	return IO{
+		In:  c.input(),
+		Out: c.stdout(),
+		Err: c.errOutput(),
+	}
+}
+
+// UseOutput adds a function that transforms c's output for each run.
+// At the start of every call to Run, the writer that output would
+// otherwise go to is passed to transform, and everything that c writes
+// during that run, such as help and error messages, is written to the
+// writer that it returns instead. Commands that implement IOCommand
+// get it as their IO.Err. If c's ErrOutput is set, error messages are
+// written to it untransformed. If the returned writer has a Flush
+// method, it is called when Run returns.
+//
+// Transformations are applied in the order that they were added, so
+// each one is passed the writer returned by the previous one. Because
+// the transformed writer is stored in c for the duration of the run,
+// Run must not be called concurrently on a Commander that uses output
+// transformations.
+func (c *Commander) UseOutput(transform func(w io.Writer) io.Writer) {
+	c.transforms = append(c.transforms, transform)
+}
+
+// transformOutput applies c's output transformations for a single
+// run. If there are any, it returns a function that undoes them, which
+// should be called once the run is finished.
+func (c *Commander) transformOutput() func() {
+	if (len(c.transforms) == 0) || (c.runOutput != nil) {
+		return nil
+	}
+
+	w := c.output()
+	for _, transform := range c.transforms {
+		w = transform(w)
+	}
+	c.runOutput = w
+
+	return func() {
+		c.runOutput = nil
+		if f, ok := w.(interface{ Flush() error }); ok {
+			_ = f.Flush()
+		}
+	}
+}
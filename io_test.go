@@ -0,0 +1,282 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type catCmd struct {
+	in  io.Reader
+	out io.Writer
+}
+
+func (cmd *catCmd) Name() string {
+	return "cat"
+}
+
+func (cmd *catCmd) Desc() string {
+	return "copy input to output"
+}
+
+func (cmd *catCmd) Help() string {
+	return "Usage: cat"
+}
+
+func (cmd *catCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *catCmd) SetInput(r io.Reader) {
+	cmd.in = r
+}
+
+func (cmd *catCmd) Run(args []string) error {
+	data, err := ioutil.ReadAll(cmd.in)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.out.Write(bytes.ToUpper(data))
+	return err
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(sub.Command) sub.Command
+	}{
+		{name: "Direct", wrap: func(cmd sub.Command) sub.Command { return cmd }},
+		{name: "Wrapped", wrap: sub.Hidden},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			c := &sub.Commander{Input: strings.NewReader("some input")}
+			c.Register(test.wrap(&catCmd{out: &out}))
+
+			err := c.Run([]string{"subtest", "cat"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "SOME INPUT"; out.String() != want {
+				t.Errorf("Expected:\t%q", want)
+				t.Errorf("Got:\t\t%q", out.String())
+			}
+		})
+	}
+}
+
+type ioCmd struct {
+	io sub.IO
+}
+
+func (cmd *ioCmd) Name() string {
+	return "io"
+}
+
+func (cmd *ioCmd) Desc() string {
+	return "copy input to output and errors"
+}
+
+func (cmd *ioCmd) Help() string {
+	return "Usage: io"
+}
+
+func (cmd *ioCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *ioCmd) SetIO(io sub.IO) {
+	cmd.io = io
+}
+
+func (cmd *ioCmd) Run(args []string) error {
+	data, err := ioutil.ReadAll(cmd.io.In)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.io.Out, "out: %s", data)
+	fmt.Fprintf(cmd.io.Err, "err: %s", data)
+	return nil
+}
+
+func TestIO(t *testing.T) {
+	var out, errout bytes.Buffer
+
+	cmd := &ioCmd{}
+	c := &sub.Commander{IO: sub.IO{
+		In:  strings.NewReader("data"),
+		Out: &out,
+		Err: &errout,
+	}}
+	c.Register(cmd)
+
+	err := c.Run([]string{"subtest", "io"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "out: data"; out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+	if want := "err: data"; errout.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", errout.String())
+	}
+}
+
+func TestIOFallback(t *testing.T) {
+	var out bytes.Buffer
+	in := strings.NewReader("")
+
+	cmd := &ioCmd{}
+	c := &sub.Commander{Output: &out, Input: in}
+	c.Register(cmd)
+
+	err := c.Run([]string{"subtest", "io"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.io.In != in {
+		t.Errorf("In did not fall back to Input")
+	}
+	if cmd.io.Out != os.Stdout {
+		t.Errorf("Out did not fall back to os.Stdout")
+	}
+	if cmd.io.Err != &out {
+		t.Errorf("Err did not fall back to Output")
+	}
+}
+
+func TestIOErrPrecedence(t *testing.T) {
+	var out, errout bytes.Buffer
+
+	c := &sub.Commander{Output: &out, IO: sub.IO{Err: &errout}}
+	c.Run([]string{"subtest", "missing"})
+
+	if out.Len() != 0 {
+		t.Errorf("Output used: %q", out.String())
+	}
+	if !strings.HasPrefix(errout.String(), "Error: No such command") {
+		t.Errorf("Got:\t\t%q", errout.String())
+	}
+}
+
+func TestStandardIO(t *testing.T) {
+	io := sub.StandardIO()
+	if (io.In != os.Stdin) || (io.Out != os.Stdout) || (io.Err != os.Stderr) {
+		t.Errorf("Got:\t\t%#v", io)
+	}
+}
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (w upperWriter) Write(data []byte) (int, error) {
+	return w.w.Write(bytes.ToUpper(data))
+}
+
+func TestUseOutput(t *testing.T) {
+	var cout bytes.Buffer
+	var flushed, transformed int
+
+	c := &sub.Commander{Output: &cout}
+	c.RegisterAll(c.HelpCmd(), sub.Func("test", "a test", "", nil, nil))
+	c.UseOutput(func(w io.Writer) io.Writer {
+		transformed++
+		return upperWriter{w: w}
+	})
+	c.UseOutput(func(w io.Writer) io.Writer {
+		return flushWriter{Writer: w, flush: func() { flushed++ }}
+	})
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "USAGE: SUBTEST <SUBCOMMAND> [SUBCOMMAND ARGUMENTS]\n\nCOMMANDS:\n\tHELP  SHOW HELP FOR COMMANDS\n\tTEST  A TEST\n"
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+	if (transformed != 1) || (flushed != 1) {
+		t.Errorf("Transformed %v times and flushed %v times", transformed, flushed)
+	}
+
+	cout.Reset()
+	err = c.HelpCmd().Run(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out := cout.String(); !strings.HasPrefix(out, "Usage:") {
+		t.Errorf("Output still transformed after Run: %q", out)
+	}
+}
+
+type flushWriter struct {
+	io.Writer
+	flush func()
+}
+
+func (w flushWriter) Flush() error {
+	w.flush()
+	return nil
+}
+
+func TestErrOutput(t *testing.T) {
+	var cout, cerr bytes.Buffer
+
+	c := &sub.Commander{Output: &cout, ErrOutput: &cerr}
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.Deprecated(sub.Func("old", "", "", nil, func([]string) error { return nil }), "don't"),
+	)
+
+	err := c.Run([]string{"subtest", "missing"})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if out := cerr.String(); !strings.HasPrefix(out, "Error: No such command") {
+		t.Errorf("Error output:\t%q", out)
+	}
+	if out := cout.String(); !strings.HasPrefix(out, "Usage:") {
+		t.Errorf("Output:\t%q", out)
+	}
+
+	cout.Reset()
+	cerr.Reset()
+	err = c.Run([]string{"subtest", "old"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "Warning: command \"old\" is deprecated: don't\n"; cerr.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cerr.String())
+	}
+	if cout.Len() != 0 {
+		t.Errorf("Output:\t%q", cout.String())
+	}
+
+	cerr.Reset()
+	err = c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cerr.Len() != 0 {
+		t.Errorf("Error output:\t%q", cerr.String())
+	}
+	if cout.Len() == 0 {
+		t.Errorf("Help was not written to Output")
+	}
+}
@@ -0,0 +1,69 @@
+package sub
+
+import (
+	"context"
+	"flag"
+	"sync"
+)
+
+type lazyCmd struct {
+	name    string
+	desc    string
+	factory func() Command
+
+	once sync.Once
+	cmd  Command
+}
+
+// Lazy returns a Command that creates the Command that it represents
+// by calling factory the first time that its Help, Flags, Run, or
+// RunContext methods are called, which can be useful if creating it is
+// expensive. factory is called at most once, even if those methods are
+// called concurrently, and the Command that it returns is used from
+// then on. The returned Command's Name and Desc methods return name
+// and desc, so listing the command in help output doesn't create it.
+//
+// For the same reason, the returned Command doesn't implement any of
+// the package's optional interfaces, such as AliasedCommand, other
+// than CommandContext. To give it aliases, a group, or so on, wrap it
+// with the appropriate function, such as WithGroup.
+func Lazy(name, desc string, factory func() Command) Command {
+	return &lazyCmd{
+		name:    name,
+		desc:    desc,
+		factory: factory,
+	}
+}
+
+// get returns the Command returned by factory, calling it if it
+// hasn't been called yet.
+func (cmd *lazyCmd) get() Command {
+	cmd.once.Do(func() {
+		cmd.cmd = cmd.factory()
+	})
+	return cmd.cmd
+}
+
+func (cmd *lazyCmd) Name() string {
+	return cmd.name
+}
+
+func (cmd *lazyCmd) Desc() string {
+	return cmd.desc
+}
+
+func (cmd *lazyCmd) Help() string {
+	return cmd.get().Help()
+}
+
+func (cmd *lazyCmd) Flags(fset *flag.FlagSet) {
+	cmd.get().Flags(fset)
+}
+
+func (cmd *lazyCmd) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+func (cmd *lazyCmd) RunContext(ctx context.Context, args []string) error {
+	return runCommand(ctx, cmd.get(), args)
+}
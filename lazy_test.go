@@ -0,0 +1,67 @@
+package sub_test
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestLazy(t *testing.T) {
+	var calls int32
+	var ran int32
+	cmd := sub.Lazy("open", "open the database", func() sub.Command {
+		atomic.AddInt32(&calls, 1)
+		return sub.Func("open", "open the database", "Opens the database.", nil, func([]string) error {
+			atomic.AddInt32(&ran, 1)
+			return nil
+		})
+	})
+
+	var out bytes.Buffer
+	c := &sub.Commander{Output: &out}
+	c.RegisterAll(c.HelpCmd(), cmd)
+	if help := c.HelpString(); !bytes.Contains([]byte(help), []byte("open the database")) {
+		t.Errorf("Unexpected help: %q", help)
+	}
+	if cmd.Name() != "open" {
+		t.Errorf("Expected:\t%q", "open")
+		t.Errorf("Got:\t\t%q", cmd.Name())
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Fatalf("Factory called %v times before use", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cmd.Run(nil)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Factory called %v times", n)
+	}
+	err := c.Run([]string{"subtest", "open"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Factory called %v times", n)
+	}
+	if n := atomic.LoadInt32(&ran); n != 11 {
+		t.Errorf("Command ran %v times", n)
+	}
+	if help := cmd.Help(); help != "Opens the database." {
+		t.Errorf("Expected:\t%q", "Opens the database.")
+		t.Errorf("Got:\t\t%q", help)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !nosync
+// +build !nosync
+
+package sub
+
+import "sync"
+
+// rwMutex guards the commands registered with a Commander. Building
+// with the nosync tag replaces it with a no-op.
+type rwMutex struct {
+	sync.RWMutex
+}
@@ -0,0 +1,13 @@
+//go:build nosync
+// +build nosync
+
+package sub
+
+// rwMutex is a no-op replacement for sync.RWMutex for programs that
+// never register commands concurrently.
+type rwMutex struct{}
+
+func (*rwMutex) Lock()    {}
+func (*rwMutex) Unlock()  {}
+func (*rwMutex) RLock()   {}
+func (*rwMutex) RUnlock() {}
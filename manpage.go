@@ -0,0 +1,118 @@
+package sub
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ManPage writes a man page for c to w in the troff format used by
+// man(1). version and date are placed in the page's header and footer.
+// The page contains the Commander's usage, its help text and global
+// flags, and a subsection for every command listed in its help,
+// including each command's own help text and flags.
+func ManPage(c *Commander, w io.Writer, version, date string) error {
+	h := &helpCmd{Commander: c}
+	name := h.progName()
+	mw := &errWriter{w: w}
+
+	summary := name
+	if help := strings.TrimSpace(c.Help); help != "" {
+		summary += " - " + strings.SplitN(help, "\n", 2)[0]
+	}
+
+	fmt.Fprintf(mw, ".TH %v 1 %v %v\n", roffQuote(strings.ToUpper(name)), roffQuote(date), roffQuote(name+" "+version))
+	fmt.Fprintf(mw, ".SH NAME\n%v\n", roffEscape(summary))
+
+	globalOptions := ""
+	if c.hasGlobalFlags() {
+		globalOptions = "[global options] "
+	}
+	fmt.Fprintf(mw, ".SH SYNOPSIS\n.B %v\n%v<subcommand> [subcommand arguments]\n", roffEscape(name), roffEscape(globalOptions))
+
+	if help := strings.TrimSpace(c.Help); help != "" {
+		fmt.Fprintf(mw, ".SH DESCRIPTION\n%v\n", roffText(help))
+	}
+
+	if c.hasGlobalFlags() {
+		fset := flag.NewFlagSet(name, flag.ContinueOnError)
+		c.globalFlags(fset)
+		fmt.Fprintf(mw, ".SH OPTIONS\n")
+		manFlags(mw, fset)
+	}
+
+	fmt.Fprintf(mw, ".SH COMMANDS\n")
+	for _, cmd := range h.listed() {
+		fmt.Fprintf(mw, ".SS %v\n%v\n", roffQuote(h.displayName(cmd)), roffText(h.describe(cmd)))
+		if help := strings.TrimSpace(cmd.Help()); help != "" {
+			fmt.Fprintf(mw, ".PP\n.nf\n%v\n.fi\n", roffText(help))
+		}
+
+		fset := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+		c.cmdFlags(cmd, fset)
+		manFlags(mw, fset)
+	}
+
+	return mw.err
+}
+
+// manFlags writes a .TP list entry for each flag in fset.
+func manFlags(w io.Writer, fset *flag.FlagSet) {
+	fset.VisitAll(func(f *flag.Flag) {
+		typ, usage := flag.UnquoteUsage(f)
+
+		fmt.Fprintf(w, ".TP\n.B \\-%v", roffEscape(f.Name))
+		if typ != "" {
+			fmt.Fprintf(w, " \" \\fI%v\\fR\"", roffEscape(typ))
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprint(w, roffText(usage))
+		if (f.DefValue != "") && (f.DefValue != "false") && (f.DefValue != "0") {
+			fmt.Fprintf(w, " (default %v)", roffEscape(f.DefValue))
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// roffEscape escapes characters in text that have special meaning to
+// troff.
+func roffEscape(text string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+}
+
+// roffText escapes text that may span multiple lines, making sure that
+// no line is interpreted as a request.
+func roffText(text string) string {
+	lines := strings.Split(roffEscape(text), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roffQuote escapes text and quotes it for use as a single macro
+// argument.
+func roffQuote(text string) string {
+	return `"` + strings.Replace(roffEscape(text), `"`, `""`, -1) + `"`
+}
+
+// errWriter is an io.Writer that records the first error returned by
+// the underlying io.Writer and discards all writes after that.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) Write(data []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(data)
+	w.err = err
+	return n, err
+}
@@ -0,0 +1,52 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestManPage(t *testing.T) {
+	c := sub.NewCommander(
+		sub.WithName("subtest"),
+		sub.WithHelp("A program for testing.\n.Lines starting with dots are escaped."),
+		sub.WithFlags(func(fset *flag.FlagSet) {
+			fset.Bool("v", false, "verbose output")
+		}),
+	)
+	c.RegisterAll(c.HelpCmd(), &testCmd{})
+
+	var buf bytes.Buffer
+	err := sub.ManPage(c, &buf, "1.0.0", "2019-01-01")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, macro := range []string{
+		`.TH "SUBTEST" 1 "2019\-01\-01" "subtest 1.0.0"`,
+		".SH NAME\nsubtest \\- A program for testing.\n",
+		".SH SYNOPSIS",
+		".SH DESCRIPTION",
+		".SH OPTIONS",
+		".SH COMMANDS",
+		`.SS "help"`,
+		`.SS "test"`,
+		".TP\n.B \\-flag \" \\fIstring\\fR\"\na flag test (default test)\n",
+	} {
+		if !strings.Contains(out, macro) {
+			t.Errorf("Output is missing %q", macro)
+		}
+	}
+
+	request := regexp.MustCompile(`^\.(TH|SH|SS|TP|PP|B|nf|fi)( |$)`)
+	for _, line := range strings.Split(out, "\n") {
+		if (strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'")) && !request.MatchString(line) {
+			t.Errorf("Invalid request: %q", line)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package sub
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// markdown writes help as a Markdown document.
+func (h *helpCmd) markdown(args []string) error {
+	w := h.output()
+
+	if len(args) == 0 {
+		data := h.helpData(false)
+
+		fmt.Fprintf(w, "# %v\n", data.Name)
+		if data.Help != "" {
+			fmt.Fprintf(w, "\n%v\n", data.Help)
+		}
+		if data.LongHelp != "" {
+			fmt.Fprintf(w, "\n%v\n", data.LongHelp)
+		}
+		fmt.Fprintf(w, "\n## Usage\n\n```\n%v\n```\n", data.Usage)
+		if h.hasGlobalFlags() {
+			fmt.Fprintf(w, "\n## Global Options\n\n```\n%v```\n", h.globalDefaults())
+		}
+
+		if header := strings.TrimSpace(h.Header); header != "" {
+			fmt.Fprintf(w, "\n%v\n", header)
+		}
+		fmt.Fprintf(w, "\n## Commands\n\n| Command | Description |\n| --- | --- |\n")
+		for _, cmd := range data.Commands {
+			fmt.Fprintf(w, "| `%v` | %v |\n", h.displayName(cmd.cmd), markdownCell(h.describe(cmd.cmd)))
+		}
+		if footer := strings.TrimSpace(h.Footer); footer != "" {
+			fmt.Fprintf(w, "\n%v\n", footer)
+		}
+
+		return nil
+	}
+
+	cmd := h.Lookup(args[0])
+	if cmd == nil {
+		h.printNotFound(w, args[0])
+		return flag.ErrHelp
+	}
+
+	if nested := nestedOf(cmd); nested != nil {
+		nested.Commander.SetName(h.progName() + " " + nested.name)
+		return h.clone(nested.Commander).Run(args[1:])
+	}
+
+	fmt.Fprintf(w, "# %v %v\n", h.progName(), h.transformName(cmd.Name()))
+	if msg := deprecation(cmd); msg != "" {
+		fmt.Fprintf(w, "\n**Deprecated:** %v\n", msg)
+	}
+	if cmd.Help() != "" {
+		fmt.Fprintf(w, "\n%v\n", strings.TrimSpace(cmd.Help()))
+	}
+	if defaults := h.cmdDefaults(cmd); defaults != "" {
+		fmt.Fprintf(w, "\n## Options\n\n```\n%v```\n", defaults)
+	}
+	if examples := examplesOf(cmd); len(examples) > 0 {
+		fmt.Fprintf(w, "\n## Examples\n\n```\n%v\n```\n", strings.Join(examples, "\n\n"))
+	}
+	if names := seeAlsoOf(cmd); len(names) > 0 {
+		fmt.Fprintf(w, "\n## See Also\n\n")
+		for _, name := range names {
+			fmt.Fprintf(w, "- `%v`\n", name)
+		}
+	}
+
+	return nil
+}
+
+// markdownCell escapes text for use in a Markdown table cell.
+func markdownCell(text string) string {
+	return strings.Replace(text, "|", `\|`, -1)
+}
@@ -0,0 +1,109 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestMarkdownHelp(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		cout string
+	}{
+		{
+			name: "Summary",
+			args: []string{"subtest", "help", "-format", "markdown"},
+			cout: "# subtest\n" +
+				"\n" +
+				"Some help.\n" +
+				"\n" +
+				"## Usage\n" +
+				"\n" +
+				"```\n" +
+				"Usage: subtest [global options] <subcommand> [subcommand arguments]\n" +
+				"```\n" +
+				"\n" +
+				"## Global Options\n" +
+				"\n" +
+				"```\n" +
+				"  -v\tverbose output\n" +
+				"```\n" +
+				"\n" +
+				"## Commands\n" +
+				"\n" +
+				"| Command | Description |\n" +
+				"| --- | --- |\n" +
+				"| `help` | show help for commands |\n" +
+				"| `pipe` | a \\| b |\n" +
+				"| `test` | a simple test |\n",
+		},
+		{
+			name: "Command",
+			args: []string{"subtest", "help", "-format=markdown", "test"},
+			cout: "# subtest test\n" +
+				"\n" +
+				"This is just a simple test.\n" +
+				"No, really. That's it.\n" +
+				"Probably.\n" +
+				"\n" +
+				"## Options\n" +
+				"\n" +
+				"```\n" +
+				"  -flag string\n" +
+				"    \ta flag test (default \"test\")\n" +
+				"```\n",
+		},
+		{
+			name: "Text Help Hides Format",
+			args: []string{"subtest", "help", "help"},
+			cout: `Usage: help [options] [command]
+
+help displays a help summary for the entire set of commands or it
+shows more detailed help for a specific named subcommand. With the
+-long flag, the summary also includes the program's extended
+description, if it has one.
+
+Options:
+  -all
+    	include hidden commands in the summary
+  -long
+    	include the extended description in the summary
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+
+			c := &sub.Commander{
+				Output: &cout,
+				Help:   "Some help.",
+				Flags: func(fset *flag.FlagSet) {
+					fset.Bool("v", false, "verbose output")
+				},
+			}
+			c.RegisterAll(
+				c.HelpCmd(),
+				&testCmd{},
+				sub.Func("pipe", "a | b", "", nil, nil),
+			)
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package sub
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// shallow returns a new Commander with the same configuration as c but
+// no commands.
+func (c *Commander) shallow() *Commander {
+	s := &Commander{
+		Output:             c.Output,
+		ErrOutput:          c.ErrOutput,
+		Input:              c.Input,
+		IO:                 c.IO,
+		Help:               c.Help,
+		LongHelp:           c.LongHelp,
+		Header:             c.Header,
+		Footer:             c.Footer,
+		Flags:              c.Flags,
+		PersistentFlags:    c.PersistentFlags,
+		AutoVersion:        c.AutoVersion,
+		Default:            c.Default,
+		NotFound:           c.NotFound,
+		OnNoArgs:           c.OnNoArgs,
+		HelpPadding:        c.HelpPadding,
+		MaxWidth:           c.MaxWidth,
+		NoColor:            c.NoColor,
+		HelpRenderer:       c.HelpRenderer,
+		HelpTemplate:       c.HelpTemplate,
+		PreRun:             c.PreRun,
+		PostRun:            c.PostRun,
+		EnvPrefix:          c.EnvPrefix,
+		Silent:             c.Silent,
+		ErrorHandler:       c.ErrorHandler,
+		ErrorPrefix:        c.ErrorPrefix,
+		Interspersed:       c.Interspersed,
+		AutoHelp:           c.AutoHelp,
+		DisableBuiltinHelp: c.DisableBuiltinHelp,
+		Sort:               c.Sort,
+		Timeout:            c.Timeout,
+		Recover:            c.Recover,
+		DryRun:             c.DryRun,
+		NormalizeNames:     c.NormalizeNames,
+		Abbreviate:         c.Abbreviate,
+		NameTransform:      c.NameTransform,
+		MinArgs:            c.MinArgs,
+		MaxArgs:            c.MaxArgs,
+		FlagErrorHandling:  c.FlagErrorHandling,
+		UsageFunc:          c.UsageFunc,
+		CommandUsageFunc:   c.CommandUsageFunc,
+		Prompt:             c.Prompt,
+
+		version:    c.version,
+		parent:     c.parent,
+		middleware: append([]MiddlewareFunc(nil), c.middleware...),
+		transforms: append([]func(io.Writer) io.Writer(nil), c.transforms...),
+	}
+	s.SetName(c.Name())
+	return s
+}
+
+// Clone returns a new Commander with the same configuration and
+// commands as c. The clone's set of commands is independent of c's, so
+// commands can be registered with or unregistered from either one
+// without affecting the other, and its fields can be changed, such as
+// to give it a different Output, without affecting c. State from
+// previous runs, such as the global FlagSet, is not copied.
+//
+// The commands themselves are shared rather than copied, with the
+// exception of those returned by c's HelpCmd, CompletionCmd, and
+// VersionCmd methods, which are replaced with equivalents for the
+// clone, and of nested Commanders registered via AsCommand, which are
+// cloned in turn so that they inherit the clone's Output, Silent, and
+// other fields instead of c's. This is also true of such commands when
+// they are wrapped, such as with Hidden.
+func (c *Commander) Clone() *Commander {
+	clone := c.shallow()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clone.seq = c.seq
+	clone.commands = make([]entry, 0, len(c.commands))
+	rebound := make(map[string]Command)
+	for _, e := range c.commands {
+		cmd, ok := rebound[e.cmd.Name()]
+		if !ok {
+			cmd = rebind(e.cmd, c, clone)
+			rebound[e.cmd.Name()] = cmd
+		}
+		e.cmd = cmd
+		clone.commands = append(clone.commands, e)
+	}
+
+	return clone
+}
+
+// keepDefault updates the Default of c, which was created from from,
+// once c's commands have been added. If from's Default is one of its
+// registered commands, c's Default is set to the command registered
+// under the same name in c, or to nil if there is none, so that a
+// command that was left out can't be run by giving no arguments. A
+// Default that isn't registered is left alone.
+func (c *Commander) keepDefault(from *Commander) {
+	if (c.Default == nil) || !from.Has(c.Default.Name()) {
+		return
+	}
+	c.Default = c.Lookup(c.Default.Name())
+}
+
+// rebind returns an equivalent of cmd for to if cmd is one of the
+// commands that from's methods create for it, such as with HelpCmd, or
+// a Commander nested in from, either of which may be wrapped.
+// Otherwise, it returns cmd.
+func rebind(cmd Command, from, to *Commander) Command {
+	cmd, _ = rebound(cmd, from, to)
+	return cmd
+}
+
+// rebound is rebind, but also returns whether or not cmd was replaced.
+func rebound(cmd Command, from, to *Commander) (Command, bool) {
+	switch cmd := cmd.(type) {
+	case *helpCmd:
+		if cmd.Commander == from {
+			return cmd.clone(to), true
+		}
+	case *completionCmd:
+		if cmd.c == from {
+			return &completionCmd{c: to, output: cmd.output}, true
+		}
+	case *versionCmd:
+		if cmd.c == from {
+			return &versionCmd{c: to}, true
+		}
+	case *commanderCmd:
+		if cmd.Commander.parent == from {
+			nested := cmd.Commander.Clone()
+			nested.parent = to
+			return &commanderCmd{Commander: nested, name: cmd.name, desc: cmd.desc}, true
+		}
+	case interface {
+		inner() Command
+		rewrap(Command) Command
+	}:
+		if inner, ok := rebound(cmd.inner(), from, to); ok {
+			return cmd.rewrap(inner), true
+		}
+	}
+	return cmd, false
+}
+
+// Merge returns a new Commander containing the commands of both c and
+// other. The new Commander has c's configuration, including its Help,
+// Flags, and Output fields, and its name, falling back to other's name
+// if c has none. If both have a command with the same name, other's
+// command wins. The second return value is the number of such
+// conflicts.
+//
+// Neither c nor other are modified. As with Clone, the commands
+// themselves are shared rather than copied, except for those created
+// by the HelpCmd, CompletionCmd, and VersionCmd methods of c and
+// other and nested Commanders, which are replaced with equivalents for
+// the merged Commander.
+func (c *Commander) Merge(other *Commander) (*Commander, int) {
+	merged := c.shallow()
+	if merged.Name() == "" {
+		merged.SetName(other.Name())
+	}
+	for _, cmd := range c.Commands() {
+		merged.add(rebind(cmd, c, merged))
+	}
+
+	var conflicts int
+	for _, cmd := range other.Commands() {
+		if merged.Has(cmd.Name()) {
+			conflicts++
+		}
+		merged.add(rebind(cmd, other, merged))
+	}
+	merged.keepDefault(c)
+
+	return merged, conflicts
+}
+
+// Subset returns a new Commander with c's configuration and only the
+// commands with the given names or aliases. Names that don't match any
+// command are skipped, and a non-nil error listing them is returned
+// along with the Commander.
+//
+// The new Commander is a shallow copy, and shares the original command
+// implementations with c, except for those replaced as by Clone. c's
+// Default is kept only if it is one of the named commands.
+func (c *Commander) Subset(names ...string) (*Commander, error) {
+	subset := c.shallow()
+
+	var missing []string
+	for _, name := range names {
+		cmd := c.Lookup(name)
+		if cmd == nil {
+			missing = append(missing, strconv.Quote(name))
+			continue
+		}
+		subset.add(rebind(cmd, c, subset))
+	}
+	subset.keepDefault(c)
+
+	if len(missing) != 0 {
+		return subset, fmt.Errorf("no such command: %v", strings.Join(missing, ", "))
+	}
+	return subset, nil
+}
+
+// Filter returns a new Commander with c's configuration and only the
+// commands for which keep returns true. Commands that are filtered out
+// can not be run or looked up through the new Commander, including by
+// any of their aliases.
+//
+// Filtering out c's Default also removes it as the Default of the new
+// Commander, and a help command created by c.HelpCmd is replaced, as
+// by Clone, so that it lists only the commands that were kept. Like
+// Subset, the new Commander otherwise shares the original command
+// implementations with c.
+func (c *Commander) Filter(keep func(cmd Command) bool) *Commander {
+	filtered := c.shallow()
+	for _, cmd := range c.Commands() {
+		if keep(cmd) {
+			filtered.add(rebind(cmd, c, filtered))
+		}
+	}
+	filtered.keepDefault(c)
+	return filtered
+}
+
+// Map returns a new Commander with c's configuration and the commands
+// returned by calling transform with each of c's commands. If
+// transform returns nil, the command is left out, so Map can filter
+// commands at the same time as transforming them. The commands are
+// registered under their new names, so if transform renames commands
+// such that two of them share a name, only one is kept. Commands
+// created by c's HelpCmd, CompletionCmd, and VersionCmd methods are
+// replaced, as by Clone, before they are passed to transform, and c's
+// Default is replaced by whatever is registered under its name once
+// the transformation is complete.
+//
+// Neither c nor its commands are modified.
+func (c *Commander) Map(transform func(cmd Command) Command) *Commander {
+	mapped := c.shallow()
+	for _, cmd := range c.Commands() {
+		if cmd = transform(rebind(cmd, c, mapped)); cmd != nil {
+			mapped.add(cmd)
+		}
+	}
+	mapped.keepDefault(c)
+	return mapped
+}
@@ -0,0 +1,393 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func commandNames(c *sub.Commander) []string {
+	var names []string
+	for _, cmd := range c.Commands() {
+		names = append(names, cmd.Name())
+	}
+	return names
+}
+
+func TestMerge(t *testing.T) {
+	var out bytes.Buffer
+	var ran string
+
+	a := sub.NewCommander(sub.WithName("a"), sub.WithOutput(&out), sub.WithHelp("Help for a."))
+	a.Register(sub.Func("build", "", "", nil, func([]string) error { ran = "a build"; return nil }))
+	a.Register(sub.Func("run", "", "", nil, func([]string) error { ran = "a run"; return nil }))
+
+	b := sub.NewCommander(sub.WithName("b"))
+	b.Register(sub.Func("run", "", "", nil, func([]string) error { ran = "b run"; return nil }))
+	b.Register(sub.Func("test", "", "", nil, func([]string) error { ran = "b test"; return nil }))
+
+	merged, conflicts := a.Merge(b)
+	if conflicts != 1 {
+		t.Errorf("Expected:\t%v", 1)
+		t.Errorf("Got:\t\t%v", conflicts)
+	}
+	if merged.Help != "Help for a." {
+		t.Errorf("Expected:\t%q", "Help for a.")
+		t.Errorf("Got:\t\t%q", merged.Help)
+	}
+	if merged.Output != &out {
+		t.Errorf("Output not copied from receiver")
+	}
+
+	want := []string{"build", "run", "test"}
+	if names := commandNames(merged); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+
+	err := merged.Run([]string{"a", "run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ran != "b run" {
+		t.Errorf("Expected:\t%q", "b run")
+		t.Errorf("Got:\t\t%q", ran)
+	}
+
+	if names := commandNames(a); !reflect.DeepEqual(names, []string{"build", "run"}) {
+		t.Errorf("Receiver modified: %q", names)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	var out bytes.Buffer
+
+	c := &sub.Commander{Output: &out, Help: "Help text."}
+	c.Register(&aliasedCmd{})
+	c.Register(sub.Func("build", "", "", nil, nil))
+	c.Register(sub.Func("run", "", "", nil, nil))
+
+	subset, err := c.Subset("run", "del")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subset.Help != "Help text." {
+		t.Errorf("Expected:\t%q", "Help text.")
+		t.Errorf("Got:\t\t%q", subset.Help)
+	}
+	if subset.Output != &out {
+		t.Errorf("Output not copied from original")
+	}
+
+	want := []string{"rm", "run"}
+	if names := commandNames(subset); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+}
+
+func TestSubsetMissing(t *testing.T) {
+	var c sub.Commander
+	c.Register(sub.Func("run", "", "", nil, nil))
+
+	subset, err := c.Subset("run", "build", "test")
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if want := `no such command: "build", "test"`; err.Error() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", err)
+	}
+
+	if names := commandNames(subset); !reflect.DeepEqual(names, []string{"run"}) {
+		t.Errorf("Expected:\t%q", []string{"run"})
+		t.Errorf("Got:\t\t%q", names)
+	}
+}
+
+func TestClone(t *testing.T) {
+	var out bytes.Buffer
+
+	c := sub.NewCommander(sub.WithName("subtest"), sub.WithOutput(&out), sub.WithHelp("Some help."))
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.Func("build", "build things", "", nil, func([]string) error { return nil }),
+		&aliasedCmd{},
+	)
+
+	clone := c.Clone()
+	if clone.Help != "Some help." {
+		t.Errorf("Expected:\t%q", "Some help.")
+		t.Errorf("Got:\t\t%q", clone.Help)
+	}
+	if clone.Name() != "subtest" {
+		t.Errorf("Expected:\t%q", "subtest")
+		t.Errorf("Got:\t\t%q", clone.Name())
+	}
+
+	clone.Unregister("build")
+	clone.Register(sub.Func("test", "test things", "", nil, nil))
+
+	want := []string{"build", "help", "rm"}
+	if names := commandNames(c); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+	want = []string{"help", "rm", "test"}
+	if names := commandNames(clone); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+	for _, alias := range (&aliasedCmd{}).Aliases() {
+		if !clone.Has(alias) {
+			t.Errorf("Alias %q not copied", alias)
+		}
+	}
+
+	var cloneOut bytes.Buffer
+	clone.Output = &cloneOut
+	err := clone.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Original output was written to: %q", out.String())
+	}
+	if !bytes.Contains(cloneOut.Bytes(), []byte("test things")) {
+		t.Errorf("Help does not describe the clone: %q", cloneOut.String())
+	}
+}
+
+func TestCloneNested(t *testing.T) {
+	var out bytes.Buffer
+
+	var inner sub.Commander
+	inner.Register(sub.Func("add", "add a remote", "Usage: add <name>", nil, nil))
+
+	c := &sub.Commander{Output: &out}
+	c.SetName("subtest")
+	c.RegisterAll(
+		sub.Hidden(c.HelpCmd()),
+		sub.WithGroup(inner.AsCommand("remote", "manage remotes"), "Remotes"),
+	)
+
+	var cloneOut bytes.Buffer
+	clone := c.Clone()
+	clone.Output = &cloneOut
+	clone.Register(sub.Func("extra", "only in the clone", "", nil, nil))
+
+	err := clone.Run([]string{"subtest", "remote", "add", "-h"})
+	if err != flag.ErrHelp {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "Usage: add <name>"; !strings.Contains(cloneOut.String(), want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cloneOut.String())
+	}
+
+	cloneOut.Reset()
+	err = clone.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(cloneOut.String(), "only in the clone") {
+		t.Errorf("Help does not describe the clone: %q", cloneOut.String())
+	}
+	if out.Len() != 0 {
+		t.Errorf("Original output was written to: %q", out.String())
+	}
+
+	grouped, ok := clone.Lookup("remote").(sub.GroupedCommand)
+	if !ok || (grouped.Group() != "Remotes") {
+		t.Errorf("Nested Commander lost its wrapper: %#v", clone.Lookup("remote"))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var inner sub.Commander
+	inner.Register(sub.Func("get", "get a value", "", nil, nil))
+
+	c := sub.NewCommander(sub.WithName("subtest"), sub.WithOutput(new(bytes.Buffer)), sub.WithHelp("Some help."))
+	c.RegisterAll(
+		sub.WithGroup(sub.Func("drop", "drop the database", "", nil, func([]string) error { return nil }), "admin"),
+		sub.Func("list", "list things", "", nil, func([]string) error { return nil }),
+		&aliasedCmd{},
+		inner.AsCommand("config", "manage configuration"),
+	)
+
+	filtered := c.Filter(func(cmd sub.Command) bool {
+		grouped, ok := cmd.(sub.GroupedCommand)
+		return !ok || (grouped.Group() != "admin")
+	})
+	if filtered.Help != "Some help." {
+		t.Errorf("Expected:\t%q", "Some help.")
+		t.Errorf("Got:\t\t%q", filtered.Help)
+	}
+
+	var got []string
+	filtered.Walk(func(cmd sub.Command, depth int) {
+		got = append(got, cmd.Name())
+	})
+	want := []string{"config", "get", "list", "rm"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+
+	err := filtered.Run([]string{"subtest", "drop"})
+	var unknown *sub.UnknownCommandError
+	if !errors.As(err, &unknown) {
+		t.Errorf("Expected:\t%v", &sub.UnknownCommandError{Name: "drop"})
+		t.Errorf("Got:\t\t%v", err)
+	}
+
+	if !c.Has("drop") {
+		t.Errorf("Original Commander was modified")
+	}
+}
+
+func TestDerivedHelpAndDefault(t *testing.T) {
+	notAdmin := func(cmd sub.Command) bool { return cmd.Name() != "admin" }
+
+	tests := []struct {
+		name   string
+		derive func(c *sub.Commander) *sub.Commander
+	}{
+		{name: "Filter", derive: func(c *sub.Commander) *sub.Commander { return c.Filter(notAdmin) }},
+		{
+			name: "Subset",
+			derive: func(c *sub.Commander) *sub.Commander {
+				subset, _ := c.Subset("help", "list")
+				return subset
+			},
+		},
+		{
+			name: "Map",
+			derive: func(c *sub.Commander) *sub.Commander {
+				return c.Map(func(cmd sub.Command) sub.Command {
+					if !notAdmin(cmd) {
+						return nil
+					}
+					return cmd
+				})
+			},
+		},
+		{
+			name: "Merge",
+			derive: func(c *sub.Commander) *sub.Commander {
+				other := sub.NewCommander()
+				other.Register(sub.Func("extra", "an extra command", "", nil, nil))
+				merged, _ := c.Filter(notAdmin).Merge(other)
+				return merged
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ran bool
+			admin := sub.Func("admin", "administer things", "", nil, func([]string) error {
+				ran = true
+				return nil
+			})
+
+			c := sub.NewCommander(sub.WithName("subtest"))
+			c.Default = admin
+			c.RegisterAll(
+				c.HelpCmd(),
+				admin,
+				sub.Func("list", "list things", "", nil, nil),
+			)
+
+			var out bytes.Buffer
+			derived := test.derive(c)
+			derived.Output = &out
+
+			err := derived.Run([]string{"subtest"})
+			if ran {
+				t.Errorf("Filtered out Default was run")
+			}
+			if err != flag.ErrHelp {
+				t.Errorf("Expected:\t%v", flag.ErrHelp)
+				t.Errorf("Got:\t\t%v", err)
+			}
+
+			out.Reset()
+			err = derived.Run([]string{"subtest", "help"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if help := out.String(); strings.Contains(help, "admin") || !strings.Contains(help, "list things") {
+				t.Errorf("Help does not describe the new Commander: %q", help)
+			}
+		})
+	}
+}
+
+type authCmd struct {
+	sub.Command
+	ran *[]string
+}
+
+func (cmd authCmd) Run(args []string) error {
+	*cmd.ran = append(*cmd.ran, "auth "+cmd.Name())
+	return cmd.Command.Run(args)
+}
+
+func TestMap(t *testing.T) {
+	var ran []string
+	run := func(name string) func([]string) error {
+		return func([]string) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+
+	c := sub.NewCommander(sub.WithName("subtest"))
+	c.RegisterAll(
+		sub.Func("build", "", "", nil, run("build")),
+		sub.Func("secret", "", "", nil, run("secret")),
+	)
+
+	mapped := c.Map(func(cmd sub.Command) sub.Command {
+		if cmd.Name() == "secret" {
+			return nil
+		}
+		return authCmd{Command: cmd, ran: &ran}
+	})
+
+	if names := commandNames(mapped); !reflect.DeepEqual(names, []string{"build"}) {
+		t.Errorf("Expected:\t%q", []string{"build"})
+		t.Errorf("Got:\t\t%q", names)
+	}
+
+	err := mapped.Run([]string{"subtest", "build"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"auth build", "build"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", ran)
+	}
+
+	ran = nil
+	err = c.Run([]string{"subtest", "build"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"build"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("Original command was modified: %q", ran)
+	}
+	if names := commandNames(c); !reflect.DeepEqual(names, []string{"build", "secret"}) {
+		t.Errorf("Original Commander was modified: %q", names)
+	}
+}
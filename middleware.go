@@ -0,0 +1,68 @@
+package sub
+
+import (
+	"fmt"
+	"time"
+)
+
+// A RunFunc runs cmd with the given arguments.
+type RunFunc func(cmd Command, args []string) error
+
+// A MiddlewareFunc wraps the running of commands. It is passed the
+// next RunFunc in the chain and returns a RunFunc that should, in most
+// cases, call next at some point.
+type MiddlewareFunc func(next RunFunc) RunFunc
+
+// Use adds middleware that wraps the running of every command
+// dispatched by c. Middleware is applied in the order that it was
+// added, so the first middleware added is the outermost, and it
+// doesn't apply to the commands of nested Commanders.
+func (c *Commander) Use(mw ...MiddlewareFunc) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// LoggingMiddleware returns middleware that logs every command that is
+// run, along with its arguments, and logs the error returned by any
+// command that fails.
+func LoggingMiddleware(logger interface{ Printf(string, ...interface{}) }) MiddlewareFunc {
+	return func(next RunFunc) RunFunc {
+		return func(cmd Command, args []string) error {
+			logger.Printf("running command %q with arguments %q", cmd.Name(), args)
+			err := next(cmd, args)
+			if err != nil {
+				logger.Printf("command %q failed: %v", cmd.Name(), err)
+			}
+			return err
+		}
+	}
+}
+
+// RecoverMiddleware returns middleware that recovers from panics in
+// commands, returning them as errors instead.
+func RecoverMiddleware() MiddlewareFunc {
+	return func(next RunFunc) RunFunc {
+		return func(cmd Command, args []string) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("command %q panicked: %v", cmd.Name(), r)
+				}
+			}()
+
+			return next(cmd, args)
+		}
+	}
+}
+
+// TimingMiddleware returns middleware that measures how long each
+// command takes to run and passes the command's name and the duration
+// to report.
+func TimingMiddleware(report func(name string, d time.Duration)) MiddlewareFunc {
+	return func(next RunFunc) RunFunc {
+		return func(cmd Command, args []string) error {
+			start := time.Now()
+			err := next(cmd, args)
+			report(cmd.Name(), time.Since(start))
+			return err
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package sub_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+type testLogger []string
+
+func (logger *testLogger) Printf(format string, args ...interface{}) {
+	*logger = append(*logger, fmt.Sprintf(format, args...))
+}
+
+func TestUse(t *testing.T) {
+	var order []string
+	mark := func(name string) sub.MiddlewareFunc {
+		return func(next sub.RunFunc) sub.RunFunc {
+			return func(cmd sub.Command, args []string) error {
+				order = append(order, name+" before")
+				err := next(cmd, args)
+				order = append(order, name+" after")
+				return err
+			}
+		}
+	}
+
+	var c sub.Commander
+	c.Use(mark("first"), mark("second"))
+	c.Use(mark("third"))
+	c.Register(sub.Func("run", "", "", nil, func([]string) error {
+		order = append(order, "run")
+		return nil
+	}))
+
+	err := c.Run([]string{"subtest", "run"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"first before",
+		"second before",
+		"third before",
+		"run",
+		"third after",
+		"second after",
+		"first after",
+	}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", order)
+	}
+}
+
+func TestBuiltinMiddleware(t *testing.T) {
+	var logger testLogger
+	var timed []string
+
+	var c sub.Commander
+	c.Use(
+		sub.TimingMiddleware(func(name string, d time.Duration) {
+			if d <= 0 {
+				t.Errorf("Non-positive duration for %q: %v", name, d)
+			}
+			timed = append(timed, name)
+		}),
+		sub.LoggingMiddleware(&logger),
+		sub.RecoverMiddleware(),
+	)
+	c.Register(sub.Func("panic", "", "", nil, func([]string) error {
+		time.Sleep(time.Millisecond)
+		panic("oh no")
+	}))
+
+	err := c.Run([]string{"subtest", "panic", "arg"})
+	if (err == nil) || !strings.Contains(err.Error(), "oh no") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantLog := []string{
+		`running command "panic" with arguments ["arg"]`,
+		`command "panic" failed: command "panic" panicked: oh no`,
+	}
+	if !reflect.DeepEqual([]string(logger), wantLog) {
+		t.Errorf("Expected:\t%q", wantLog)
+		t.Errorf("Got:\t\t%q", logger)
+	}
+
+	if want := []string{"panic"}; !reflect.DeepEqual(timed, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", timed)
+	}
+}
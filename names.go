@@ -0,0 +1,169 @@
+package sub
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// normalizesNames returns true if c or any of its parents have
+// NormalizeNames set.
+func (c *Commander) normalizesNames() bool {
+	return c.NormalizeNames || ((c.parent != nil) && c.parent.normalizesNames())
+}
+
+// normalizeName returns name with every underscore replaced by a
+// hyphen.
+func normalizeName(name string) string {
+	return strings.Replace(name, "_", "-", -1)
+}
+
+// lookupNormalized returns the command registered under a name that
+// is the same as name once both are normalized, or nil if there isn't
+// one. The caller must hold c's read lock.
+func (c *Commander) lookupNormalized(name string) Command {
+	name = normalizeName(name)
+	for _, e := range c.commands {
+		if normalizeName(e.name) == name {
+			return e.cmd
+		}
+	}
+	return nil
+}
+
+// transformName returns name transformed by c's NameTransform, if it
+// has one.
+func (c *Commander) transformName(name string) string {
+	if c.NameTransform == nil {
+		return name
+	}
+	return c.NameTransform(name)
+}
+
+// canonical returns true if e is the entry for its command's name,
+// rather than for one of the command's aliases.
+func (c *Commander) canonical(e entry) bool {
+	return e.name == c.transformName(e.cmd.Name())
+}
+
+// lookupOriginal returns the command registered under the transformed
+// version of name, or nil if there isn't one. The caller must hold c's
+// read lock.
+func (c *Commander) lookupOriginal(name string) Command {
+	name = c.transformName(name)
+	i := c.search(name)
+	if (i < len(c.commands)) && (c.commands[i].name == name) {
+		return c.commands[i].cmd
+	}
+	return nil
+}
+
+// normalizeFlags returns args with the names of the flags in it
+// replaced by the names of the flags in fset that they match once
+// normalized, if c normalizes names. Flags that are already defined
+// under the name that they were given with are left alone. If
+// interspersed is false, only the flags before the first positional
+// argument are considered, as those are the only ones that fset will
+// parse.
+func (c *Commander) normalizeFlags(fset *flag.FlagSet, args []string, interspersed bool) []string {
+	if !c.normalizesNames() {
+		return args
+	}
+
+	normalized := make(map[string]string)
+	fset.VisitAll(func(f *flag.Flag) {
+		normalized[normalizeName(f.Name)] = f.Name
+	})
+
+	args = append([]string(nil), args...)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if (len(arg) < 2) || (arg[0] != '-') {
+			if !interspersed {
+				break
+			}
+			continue
+		}
+
+		dashes := "-"
+		if arg[1] == '-' {
+			dashes = "--"
+		}
+		name, value := arg[len(dashes):], ""
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name, value = name[:eq], name[eq:]
+		}
+
+		f := fset.Lookup(name)
+		if f == nil {
+			canonical, ok := normalized[normalizeName(name)]
+			if !ok {
+				continue
+			}
+			args[i] = dashes + canonical + value
+			f = fset.Lookup(canonical)
+		}
+
+		// A flag that takes a value and wasn't given one with = uses the
+		// next argument, which must not be mistaken for a flag or a
+		// positional argument.
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); (value == "") && !(ok && b.IsBoolFlag()) {
+			i++
+		}
+	}
+	return args
+}
+
+// abbreviates returns true if c or any of its parents have Abbreviate
+// set.
+func (c *Commander) abbreviates() bool {
+	return c.Abbreviate || ((c.parent != nil) && c.parent.abbreviates())
+}
+
+// lookupPrefix returns the only command that has a name or alias that
+// starts with prefix, along with the names of all of the commands that
+// do. If there isn't exactly one, the returned command is nil.
+func (c *Commander) lookupPrefix(prefix string) (Command, []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	normalize := c.normalizesNames()
+	if normalize {
+		prefix = normalizeName(prefix)
+	}
+
+	var cmd Command
+	var matches []string
+	seen := make(map[string]bool)
+	for _, e := range c.commands {
+		name := e.name
+		if normalize {
+			name = normalizeName(name)
+		}
+		if !strings.HasPrefix(name, prefix) || seen[e.cmd.Name()] {
+			continue
+		}
+
+		seen[e.cmd.Name()] = true
+		cmd = e.cmd
+		matches = append(matches, c.transformName(e.cmd.Name()))
+	}
+	sort.Strings(matches)
+
+	if len(matches) != 1 {
+		return nil, matches
+	}
+	return cmd, matches
+}
+
+// printAmbiguous prints the error message for a prefix that matches
+// more than one command.
+func (c *Commander) printAmbiguous(w io.Writer, name string, matches []string) {
+	fmt.Fprintf(w, "%vAmbiguous command: %q\n", c.errorPrefix(), name)
+	fmt.Fprintf(w, "It could be: %v\n", strings.Join(matches, ", "))
+}
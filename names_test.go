@@ -0,0 +1,182 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestNormalizeNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		normalize bool
+		ran       bool
+		dryRun    bool
+		verbose   bool
+		got       []string
+	}{
+		{name: "Canonical", args: []string{"subtest", "my-cmd"}, normalize: true, ran: true},
+		{name: "Underscore", args: []string{"subtest", "my_cmd"}, normalize: true, ran: true},
+		{name: "Flags", args: []string{"subtest", "-global_verbose", "my_cmd", "--dry_run=true", "-out_file", "-x_y", "arg"}, normalize: true, ran: true, dryRun: true, verbose: true, got: []string{"arg"}},
+		{name: "Positional", args: []string{"subtest", "my_cmd", "arg", "-dry_run"}, normalize: true, ran: true, got: []string{"arg", "-dry_run"}},
+		{name: "Disabled", args: []string{"subtest", "my_cmd"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ran, dryRun, verbose bool
+			var outFile string
+			var got []string
+			c := &sub.Commander{
+				NormalizeNames: test.normalize,
+				Silent:         true,
+				Flags: func(fset *flag.FlagSet) {
+					fset.BoolVar(&verbose, "global-verbose", false, "verbose output")
+				},
+			}
+			c.Register(sub.Func("my-cmd", "", "", func(fset *flag.FlagSet) {
+				fset.BoolVar(&dryRun, "dry-run", false, "don't do anything")
+				fset.StringVar(&outFile, "out-file", "", "where to write")
+			}, func(args []string) error {
+				ran = true
+				got = args
+				return nil
+			}))
+
+			err := c.Run(test.args)
+			if (err == nil) != test.ran {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if ran != test.ran {
+				t.Errorf("Expected ran to be %v", test.ran)
+			}
+			if (dryRun != test.dryRun) || (verbose != test.verbose) {
+				t.Errorf("Flags not set: dry-run=%v, global-verbose=%v", dryRun, verbose)
+			}
+			if !reflect.DeepEqual(got, test.got) {
+				t.Errorf("Expected:\t%q", test.got)
+				t.Errorf("Got:\t\t%q", got)
+			}
+			if (test.name == "Flags") && (outFile != "-x_y") {
+				t.Errorf("Expected:\t%q", "-x_y")
+				t.Errorf("Got:\t\t%q", outFile)
+			}
+		})
+	}
+}
+
+func TestAbbreviate(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        string
+		abbreviate bool
+		ran        string
+		err        error
+	}{
+		{name: "Exact", arg: "build", abbreviate: true, ran: "build"},
+		{name: "Prefix", arg: "bui", abbreviate: true, ran: "build"},
+		{name: "Ambiguous", arg: "b", abbreviate: true, err: &sub.AmbiguousCommandError{Name: "b", Matches: []string{"benchmark", "build"}}},
+		{name: "Unknown", arg: "x", abbreviate: true, err: &sub.UnknownCommandError{Name: "x"}},
+		{name: "Disabled", arg: "bui", err: &sub.UnknownCommandError{Name: "bui"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ran string
+			run := func(name string) func([]string) error {
+				return func([]string) error {
+					ran = name
+					return nil
+				}
+			}
+
+			var out bytes.Buffer
+			c := &sub.Commander{Abbreviate: test.abbreviate, IO: sub.IO{Err: &out}}
+			c.RegisterAll(
+				sub.Func("build", "", "", nil, run("build")),
+				sub.Func("benchmark", "", "", nil, run("benchmark")),
+			)
+
+			err := c.Run([]string{"subtest", test.arg})
+			if !reflect.DeepEqual(err, test.err) {
+				t.Errorf("Expected:\t%v", test.err)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if ran != test.ran {
+				t.Errorf("Expected:\t%q", test.ran)
+				t.Errorf("Got:\t\t%q", ran)
+			}
+
+			var ambiguous *sub.AmbiguousCommandError
+			if errors.As(err, &ambiguous) {
+				want := "Error: Ambiguous command: \"b\"\nIt could be: benchmark, build\n"
+				if !strings.HasPrefix(out.String(), want) {
+					t.Errorf("Expected:\t%q", want)
+					t.Errorf("Got:\t\t%q", out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNameTransform(t *testing.T) {
+	var cout bytes.Buffer
+	var got, rm bool
+
+	c := &sub.Commander{
+		Output:        &cout,
+		NameTransform: func(name string) string { return "k8s:" + name },
+	}
+	c.RegisterAll(
+		sub.Func("get", "get a resource", "", nil, func([]string) error {
+			got = true
+			return nil
+		}),
+		&aliasedCmd{ran: &rm},
+	)
+
+	err := c.Run([]string{"subtest", "-h"})
+	if err != flag.ErrHelp {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	k8s:get                      get a resource
+	k8s:rm, k8s:remove, k8s:del  delete a resource
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	for _, name := range []string{"k8s:get", "k8s:del"} {
+		err = c.Run([]string{"subtest", name})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if !got || !rm {
+		t.Errorf("Transformed commands did not run: get=%v, rm=%v", got, rm)
+	}
+
+	cmd := c.Lookup("get")
+	if (cmd == nil) || (cmd.Name() != "get") {
+		t.Errorf("Lookup of untransformed name failed: %v", cmd)
+	}
+	if c.Count() != 2 {
+		t.Errorf("Expected 2 commands, got %v", c.Count())
+	}
+}
@@ -0,0 +1,69 @@
+package sub
+
+import (
+	"flag"
+	"io"
+)
+
+// An Option configures a Commander created by NewCommander.
+type Option func(c *Commander)
+
+// NewCommander returns a new Commander configured with the given
+// options, which are applied in order. It is equivalent to creating a
+// Commander with a struct literal and then setting its fields and
+// registering its commands manually.
+func NewCommander(opts ...Option) *Commander {
+	var c Commander
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// WithName sets the name of the Commander as though by calling
+// SetName.
+func WithName(name string) Option {
+	return func(c *Commander) {
+		c.SetName(name)
+	}
+}
+
+// WithHelp sets the Commander's Help field.
+func WithHelp(help string) Option {
+	return func(c *Commander) {
+		c.Help = help
+	}
+}
+
+// WithOutput sets the Commander's Output field.
+func WithOutput(w io.Writer) Option {
+	return func(c *Commander) {
+		c.SetOutput(w)
+	}
+}
+
+// WithFlags sets the Commander's Flags field.
+func WithFlags(flags func(*flag.FlagSet)) Option {
+	return func(c *Commander) {
+		c.Flags = flags
+	}
+}
+
+// WithCommands registers the given commands, in order.
+func WithCommands(commands ...Command) Option {
+	return func(c *Commander) {
+		c.RegisterAll(commands...)
+	}
+}
+
+// WithAutoHelp sets the Commander's AutoHelp field and registers a help
+// command immediately if there isn't one already.
+func WithAutoHelp() Option {
+	return func(c *Commander) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.AutoHelp = true
+		c.autoHelp()
+	}
+}
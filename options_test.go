@@ -0,0 +1,177 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestNewCommander(t *testing.T) {
+	var cout bytes.Buffer
+	var testout bytes.Buffer
+
+	var c *sub.Commander
+	c = sub.NewCommander(
+		sub.WithName("subtest"),
+		sub.WithHelp("Some help."),
+		sub.WithOutput(&cout),
+		sub.WithFlags(func(fset *flag.FlagSet) {
+			fset.Bool("v", false, "verbose output")
+		}),
+		sub.WithCommands(
+			sub.Func("help", "show help for commands", "", nil, func(args []string) error {
+				return c.HelpCmd().Run(args)
+			}),
+			&testCmd{w: &testout},
+		),
+	)
+
+	err := c.HelpCmd().Run(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `Usage: subtest [global options] <subcommand> [subcommand arguments]
+
+Some help.
+
+Global Options:
+  -v	verbose output
+
+Commands:
+	help  show help for commands
+	test  a simple test
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	err = c.Run([]string{"subtest", "-v", "test", "arg"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out := testout.String(); out != `"arg"` {
+		t.Errorf("Expected:\t%q", `"arg"`)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestSetName(t *testing.T) {
+	var c sub.Commander
+	if name := c.Name(); name != "" {
+		t.Errorf("Expected:\t%q", "")
+		t.Errorf("Got:\t\t%q", name)
+	}
+
+	c.SetName("named")
+	c.Register(sub.Func("run", "run things", "", nil, func([]string) error { return nil }))
+	if name := c.Name(); name != "named" {
+		t.Errorf("Expected:\t%q", "named")
+		t.Errorf("Got:\t\t%q", name)
+	}
+	if out := c.HelpString(); !strings.HasPrefix(out, "Usage: named ") {
+		t.Errorf("Set name not used in help: %q", out)
+	}
+
+	err := c.Run([]string{"", "run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := c.Name(); name != "named" {
+		t.Errorf("Expected:\t%q", "named")
+		t.Errorf("Got:\t\t%q", name)
+	}
+
+	err = c.Run([]string{"other", "run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := c.Name(); name != "other" {
+		t.Errorf("Expected:\t%q", "other")
+		t.Errorf("Got:\t\t%q", name)
+	}
+}
+
+func TestAutoHelp(t *testing.T) {
+	c := &sub.Commander{AutoHelp: true}
+	if c.Has("help") {
+		t.Errorf("Help registered before any other command")
+	}
+
+	c.Register(&testCmd{})
+	if !c.Has("help") {
+		t.Fatalf("Help not registered automatically")
+	}
+	if out := c.HelpString(); !strings.Contains(out, "help  show help for commands") {
+		t.Errorf("Automatic help not listed: %q", out)
+	}
+
+	custom := sub.Func("help", "custom help", "", nil, nil)
+	c.Register(custom)
+	if desc := c.Lookup("help").Desc(); desc != "custom help" {
+		t.Errorf("Expected:\t%q", "custom help")
+		t.Errorf("Got:\t\t%q", desc)
+	}
+
+	c.RegisterAll(sub.Func("other", "", "", nil, nil))
+	if desc := c.Lookup("help").Desc(); desc != "custom help" {
+		t.Errorf("Custom help replaced: %q", desc)
+	}
+}
+
+func TestWithAutoHelp(t *testing.T) {
+	c := sub.NewCommander(
+		sub.WithCommands(&testCmd{}),
+		sub.WithAutoHelp(),
+	)
+	if !c.Has("help") {
+		t.Errorf("Help not registered by WithAutoHelp")
+	}
+	if !c.AutoHelp {
+		t.Errorf("AutoHelp not set")
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	c := sub.NewCommander()
+	if out := c.GetOutput(); out != os.Stderr {
+		t.Errorf("Expected:\t%v", os.Stderr)
+		t.Errorf("Got:\t\t%v", out)
+	}
+
+	var buf bytes.Buffer
+	c.SetOutput(&buf)
+	if out := c.GetOutput(); out != &buf {
+		t.Errorf("Expected:\t%v", &buf)
+		t.Errorf("Got:\t\t%v", out)
+	}
+	if c.Output != &buf {
+		t.Errorf("Output field not set")
+	}
+
+	c.Register(c.HelpCmd())
+	c.PrintHelp()
+	if buf.Len() == 0 {
+		t.Errorf("Nothing written to the set output")
+	}
+
+	c.SetOutput(nil)
+	if out := c.GetOutput(); out != os.Stderr {
+		t.Errorf("Expected:\t%v", os.Stderr)
+		t.Errorf("Got:\t\t%v", out)
+	}
+}
+
+func TestSetOutputNil(t *testing.T) {
+	var c *sub.Commander
+	c.SetOutput(os.Stdout)
+	if out := c.GetOutput(); out != os.Stderr {
+		t.Errorf("Expected:\t%v", os.Stderr)
+		t.Errorf("Got:\t\t%v", out)
+	}
+}
@@ -0,0 +1,84 @@
+package sub
+
+import "flag"
+
+// interspersed returns true if cmd's flags can be mixed in with its
+// positional arguments. The completion command is exempt, as the
+// arguments that the shell scripts pass to it are partially typed
+// command lines that can contain flags of other commands.
+func (c *Commander) interspersed(cmd Command) bool {
+	_, completion := cmd.(*completionCmd)
+	return c.Interspersed && !completion
+}
+
+// parseFlags parses args with fset and returns the positional
+// arguments. If interspersed is true, parsing continues past
+// positional arguments instead of stopping at the first one.
+func parseFlags(fset *flag.FlagSet, args []string, interspersed bool) ([]string, error) {
+	if !interspersed {
+		err := fset.Parse(args)
+		return fset.Args(), err
+	}
+
+	var positional []string
+	for {
+		err := fset.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		rest := fset.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		// If parsing stopped because of a "--", everything after it is
+		// positional.
+		if terminated(fset, args[:len(args)-len(rest)]) {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// terminated returns true if the flags in args, all of which have been
+// parsed by fset, were ended by a "--" rather than by a positional
+// argument. A "--" that is the value of a flag doesn't count.
+func terminated(fset *flag.FlagSet, args []string) bool {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return true
+		}
+		if _, ok := flagNeedsValue(fset, args[i]); ok {
+			i++
+		}
+	}
+	return false
+}
+
+// flagErrorHandling returns the FlagErrorHandling of c or, if it is
+// flag.ContinueOnError, of its nearest parent that has another.
+func (c *Commander) flagErrorHandling() flag.ErrorHandling {
+	if (c.FlagErrorHandling == flag.ContinueOnError) && (c.parent != nil) {
+		return c.parent.flagErrorHandling()
+	}
+	return c.FlagErrorHandling
+}
+
+// flagError handles err, the result of parsing flags, according to
+// c's FlagErrorHandling, returning it if it doesn't exit or panic.
+func (c *Commander) flagError(err error) error {
+	switch c.flagErrorHandling() {
+	case flag.ExitOnError:
+		if err == flag.ErrHelp {
+			osExit(0)
+		} else {
+			osExit(2)
+		}
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
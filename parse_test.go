@@ -0,0 +1,249 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestInterspersed(t *testing.T) {
+	tests := []struct {
+		name         string
+		interspersed bool
+		args         []string
+		verbose      bool
+		out          string
+		positional   []string
+	}{
+		{name: "Disabled", args: []string{"file.txt", "--verbose"}, positional: []string{"file.txt", "--verbose"}},
+		{name: "Flag After Arg", interspersed: true, args: []string{"file.txt", "--verbose"}, verbose: true, positional: []string{"file.txt"}},
+		{name: "Mixed", interspersed: true, args: []string{"a", "-out", "x", "b", "-verbose", "c"}, verbose: true, out: "x", positional: []string{"a", "b", "c"}},
+		{name: "Flags First", interspersed: true, args: []string{"-verbose", "a", "b"}, verbose: true, positional: []string{"a", "b"}},
+		{name: "Terminator", interspersed: true, args: []string{"a", "--", "-verbose", "b"}, positional: []string{"a", "-verbose", "b"}},
+		{name: "Dashes Value", interspersed: true, args: []string{"-out", "--", "a", "-verbose"}, verbose: true, out: "--", positional: []string{"a"}},
+		{name: "No Args", interspersed: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var verbose bool
+			var out string
+			var positional []string
+
+			c := &sub.Commander{Interspersed: test.interspersed}
+			c.Register(sub.Func("cmd", "", "", func(fset *flag.FlagSet) {
+				fset.BoolVar(&verbose, "verbose", false, "verbose output")
+				fset.StringVar(&out, "out", "", "output file")
+			}, func(args []string) error {
+				positional = args
+				return nil
+			}))
+
+			err := c.Run(append([]string{"prog", "cmd"}, test.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (verbose != test.verbose) || (out != test.out) {
+				t.Errorf("Expected:\t%v %q", test.verbose, test.out)
+				t.Errorf("Got:\t\t%v %q", verbose, out)
+			}
+			if (len(positional) != 0 || len(test.positional) != 0) && !reflect.DeepEqual(positional, test.positional) {
+				t.Errorf("Expected:\t%q", test.positional)
+				t.Errorf("Got:\t\t%q", positional)
+			}
+		})
+	}
+}
+
+func TestInterspersedError(t *testing.T) {
+	c := &sub.Commander{Interspersed: true, Silent: true}
+	c.Register(sub.Func("cmd", "", "", nil, func([]string) error { return nil }))
+
+	err := c.Run([]string{"prog", "cmd", "a", "-unknown"})
+	if _, ok := err.(*sub.FlagParseError); !ok {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDoubleDash(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		interspersed bool
+		positional   []string
+	}{
+		{name: "Command", args: []string{"prog", "cmd", "--", "--not-a-flag"}, positional: []string{"--not-a-flag"}},
+		{name: "After Flags", args: []string{"prog", "cmd", "-v", "--", "-v", "a"}, positional: []string{"-v", "a"}},
+		{name: "Empty", args: []string{"prog", "cmd", "--"}, positional: []string{}},
+		{name: "Repeated", args: []string{"prog", "cmd", "--", "--"}, positional: []string{"--"}},
+		{name: "Global", args: []string{"prog", "--", "cmd", "--", "--not-a-flag"}, positional: []string{"--not-a-flag"}},
+		{name: "Interspersed", args: []string{"prog", "cmd", "a", "--", "--not-a-flag"}, interspersed: true, positional: []string{"a", "--not-a-flag"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var positional []string
+
+			c := &sub.Commander{Interspersed: test.interspersed}
+			c.Register(sub.Func("cmd", "", "", func(fset *flag.FlagSet) {
+				fset.Bool("v", false, "verbose output")
+			}, func(args []string) error {
+				positional = args
+				return nil
+			}))
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if positional == nil {
+				positional = []string{}
+			}
+			if !reflect.DeepEqual(positional, test.positional) {
+				t.Errorf("Expected:\t%q", test.positional)
+				t.Errorf("Got:\t\t%q", positional)
+			}
+		})
+	}
+}
+
+func TestFlagErrorHandling(t *testing.T) {
+	newCommander := func(handling flag.ErrorHandling) *sub.Commander {
+		c := &sub.Commander{Output: ioutil.Discard, Silent: true, FlagErrorHandling: handling, EnvPrefix: "SUBTEST_HANDLING_"}
+		c.Register(sub.Func("cmd", "", "", func(fset *flag.FlagSet) {
+			fset.Int("n", 0, "a number")
+		}, func([]string) error { return nil }))
+		return c
+	}
+
+	t.Run("ContinueOnError", func(t *testing.T) {
+		err := newCommander(flag.ContinueOnError).Run([]string{"prog", "cmd", "-unknown"})
+		if _, ok := err.(*sub.FlagParseError); !ok {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("PanicOnError", func(t *testing.T) {
+		for _, args := range [][]string{{"prog", "-unknown", "cmd"}, {"prog", "cmd", "-unknown"}} {
+			func() {
+				defer func() {
+					r := recover()
+					if _, ok := r.(*sub.FlagParseError); !ok {
+						t.Errorf("%q: Unexpected panic: %#v", args, r)
+					}
+				}()
+
+				_ = newCommander(flag.PanicOnError).Run(args)
+				t.Errorf("%q: Run did not panic", args)
+			}()
+		}
+	})
+
+	t.Run("PanicOnError Env", func(t *testing.T) {
+		os.Setenv("SUBTEST_HANDLING_CMD_N", "many")
+		defer os.Unsetenv("SUBTEST_HANDLING_CMD_N")
+
+		defer func() {
+			r := recover()
+			if _, ok := r.(*sub.FlagParseError); !ok {
+				t.Errorf("Unexpected panic: %#v", r)
+			}
+		}()
+
+		_ = newCommander(flag.PanicOnError).Run([]string{"prog", "cmd"})
+		t.Errorf("Run did not panic")
+	})
+
+	t.Run("ExitOnError", func(t *testing.T) {
+		code := -1
+		restore := sub.SetOSExit(func(c int) { code = c })
+		defer restore()
+
+		tests := []struct {
+			args []string
+			code int
+		}{
+			{args: []string{"prog", "-unknown", "cmd"}, code: 2},
+			{args: []string{"prog", "cmd", "-unknown"}, code: 2},
+			{args: []string{"prog", "cmd", "-h"}, code: 0},
+			{args: []string{"prog", "cmd"}, code: -1},
+		}
+		for _, test := range tests {
+			code = -1
+			_ = newCommander(flag.ExitOnError).Run(test.args)
+			if code != test.code {
+				t.Errorf("%q: Expected:\t%v", test.args, test.code)
+				t.Errorf("%q: Got:\t\t%v", test.args, code)
+			}
+		}
+	})
+}
+
+func TestHelpFlagPrintsOnce(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		silent bool
+		usage  string
+	}{
+		{name: "Global", args: []string{"subtest", "--help"}, usage: "Usage: subtest "},
+		{name: "Global Silent", args: []string{"subtest", "--help"}, silent: true, usage: "Usage: subtest "},
+		{name: "Command", args: []string{"subtest", "test", "--help"}, usage: "This is just a simple test."},
+		{name: "Command Silent", args: []string{"subtest", "test", "--help"}, silent: true, usage: "This is just a simple test."},
+		{name: "Interspersed Command", args: []string{"subtest", "test", "arg", "-h"}, usage: "This is just a simple test."},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout, cerr bytes.Buffer
+			c := &sub.Commander{Output: &cout, ErrOutput: &cerr, Silent: test.silent, Interspersed: true}
+			c.RegisterAll(c.HelpCmd(), &testCmd{w: ioutil.Discard})
+
+			err := c.Run(test.args)
+			if err != flag.ErrHelp {
+				t.Errorf("Expected:\t%v", flag.ErrHelp)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if n := strings.Count(cout.String(), test.usage); n != 1 {
+				t.Errorf("Help printed %v times: %q", n, cout.String())
+			}
+			if cerr.Len() != 0 {
+				t.Errorf("Error output:\t%q", cerr.String())
+			}
+		})
+	}
+}
+
+func TestFlagErrorOutput(t *testing.T) {
+	var cout, cerr bytes.Buffer
+	c := &sub.Commander{Output: &cout, ErrOutput: &cerr}
+	c.Register(&testCmd{w: ioutil.Discard})
+
+	err := c.Run([]string{"subtest", "test", "-unknown"})
+	if !errors.Is(err, &sub.FlagParseError{Cmd: "test"}) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "flag provided but not defined: -unknown\n"; cerr.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cerr.String())
+	}
+	if n := strings.Count(cout.String(), "This is just a simple test."); n != 1 {
+		t.Errorf("Help printed %v times: %q", n, cout.String())
+	}
+}
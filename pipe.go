@@ -0,0 +1,143 @@
+package sub
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RunPipe runs a pipeline of commands in the same process, like the
+// shell pipeline
+//
+//    prog cmd1 | prog cmd2
+//
+// except that the output of each command is connected to the input of
+// the next with an io.Pipe. Each element of pipelines is a command
+// name followed by its flags and arguments, without the name of the
+// program. Every command must implement IOCommand, and no command may
+// appear more than once, as each registered command is a single
+// instance whose flags and streams can't be shared. The first
+// command reads from c's input, the last writes to c's standard
+// output, and all of them write errors to c's error output.
+//
+// Every set of arguments is parsed, as though by Parse, before any of
+// the commands are run, and RunPipe returns the first error from
+// doing so. The commands are then run concurrently. If any of them
+// returns an error, the pipeline is aborted by canceling the context
+// passed to commands that implement CommandContext and closing every
+// pipe with that error, so that the others fail when they next read
+// or write, and it is returned once they have all returned. A
+// command that fails with io.ErrClosedPipe because the next command
+// exited without reading all of its input is not considered to have
+// failed.
+//
+// Because the arguments are all parsed by c, GlobalFlagSet returns the
+// global flags of the last command in the pipeline while it is
+// running.
+func (c *Commander) RunPipe(pipelines ...[]string) error {
+	type stage struct {
+		cmd  Command
+		fset *flag.FlagSet
+		args []string
+	}
+
+	stages := make([]stage, 0, len(pipelines))
+	seen := make(map[string]bool, len(pipelines))
+	for _, args := range pipelines {
+		cmd, _, fset, rest, err := c.parse(append([]string{c.progName()}, args...))
+		if (err != nil) || (cmd == nil) {
+			c.handleError(err)
+			return err
+		}
+		if ioCommandOf(cmd) == nil {
+			err := fmt.Errorf("command %q does not implement IOCommand", cmd.Name())
+			c.handleError(err)
+			return err
+		}
+
+		// Commands parse their flags into their own fields, so a command
+		// that appeared twice would have the flags of its last
+		// appearance in both places and be run concurrently with itself.
+		if seen[cmd.Name()] {
+			err := fmt.Errorf("command %q appears more than once in the pipeline", cmd.Name())
+			c.handleError(err)
+			return err
+		}
+		seen[cmd.Name()] = true
+
+		stages = append(stages, stage{cmd: cmd, fset: fset, args: rest})
+	}
+	if len(stages) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	readers := make([]*io.PipeReader, len(stages)-1)
+	writers := make([]*io.PipeWriter, len(stages)-1)
+	for i := range readers {
+		readers[i], writers[i] = io.Pipe()
+	}
+
+	var mu sync.Mutex
+	var first error
+	abort := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if first != nil {
+			return
+		}
+		first = err
+		cancel()
+		for i := range readers {
+			readers[i].CloseWithError(err)
+			writers[i].CloseWithError(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, stage := range stages {
+		streams := c.io()
+		if i > 0 {
+			streams.In = readers[i-1]
+		}
+		if i < len(writers) {
+			streams.Out = writers[i]
+		}
+
+		ctx := ctx
+		if stage.fset != nil {
+			ctx = context.WithValue(ctx, flagSetKey{}, stage.fset)
+		}
+
+		wg.Add(1)
+		go func(ctx context.Context, i int, cmd Command, args []string, streams IO) {
+			defer wg.Done()
+
+			err := c.dispatch(ctx, cmd, args, streams)
+
+			// Closing the pipes on either side lets the next command see
+			// the end of its input and stops the previous one from
+			// blocking on a write that will never be read.
+			if i > 0 {
+				readers[i-1].Close()
+			}
+			if i < len(writers) {
+				writers[i].CloseWithError(err)
+			}
+
+			if (err != nil) && !errors.Is(err, io.ErrClosedPipe) {
+				abort(err)
+			}
+		}(ctx, i, stage.cmd, stage.args, streams)
+	}
+	wg.Wait()
+
+	c.handleError(first)
+	return first
+}
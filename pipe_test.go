@@ -0,0 +1,179 @@
+package sub_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type linesCmd struct {
+	io sub.IO
+	n  int
+}
+
+func (cmd *linesCmd) Name() string {
+	return "lines"
+}
+
+func (cmd *linesCmd) Desc() string {
+	return "write numbered lines"
+}
+
+func (cmd *linesCmd) Help() string {
+	return "Usage: lines [-n count]"
+}
+
+func (cmd *linesCmd) Flags(fset *flag.FlagSet) {
+	fset.IntVar(&cmd.n, "n", 3, "number of lines to write")
+}
+
+func (cmd *linesCmd) SetIO(io sub.IO) {
+	cmd.io = io
+}
+
+func (cmd *linesCmd) Run(args []string) error {
+	for i := 1; i <= cmd.n; i++ {
+		_, err := fmt.Fprintf(cmd.io.Out, "line %v\n", i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countCmd struct {
+	io  sub.IO
+	max int
+}
+
+func (cmd *countCmd) Name() string {
+	return "count"
+}
+
+func (cmd *countCmd) Desc() string {
+	return "count lines of input"
+}
+
+func (cmd *countCmd) Help() string {
+	return "Usage: count [-max lines]"
+}
+
+func (cmd *countCmd) Flags(fset *flag.FlagSet) {
+	fset.IntVar(&cmd.max, "max", 0, "stop after this many lines")
+}
+
+func (cmd *countCmd) SetIO(io sub.IO) {
+	cmd.io = io
+}
+
+func (cmd *countCmd) Run(args []string) error {
+	var n int
+	s := bufio.NewScanner(cmd.io.In)
+	for s.Scan() {
+		n++
+		if n == cmd.max {
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(cmd.io.Out, strconv.Itoa(n))
+	return err
+}
+
+func TestRunPipe(t *testing.T) {
+	fail := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		pipelines [][]string
+		out       string
+		err       error
+	}{
+		{name: "Single", pipelines: [][]string{{"lines"}}, out: "line 1\nline 2\nline 3\n"},
+		{name: "Two", pipelines: [][]string{{"lines", "-n", "5"}, {"count"}}, out: "5\n"},
+		{name: "Three", pipelines: [][]string{{"lines", "-n", "2"}, {"io"}, {"count"}}, out: "2\n"},
+		{name: "Early Exit", pipelines: [][]string{{"lines", "-n", "100000"}, {"count", "-max", "2"}}, out: "2\n"},
+		{name: "Error", pipelines: [][]string{{"lines", "-n", "100000"}, {"fail"}, {"count"}}, err: fail},
+		{name: "Repeated", pipelines: [][]string{{"lines", "-n", "2"}, {"count"}, {"count"}}, err: errors.New(`command "count" appears more than once in the pipeline`)},
+		{name: "Unknown", pipelines: [][]string{{"lines"}, {"missing"}}, err: &sub.UnknownCommandError{Name: "missing"}},
+		{name: "Not IOCommand", pipelines: [][]string{{"lines"}, {"plain"}}, err: errors.New(`command "plain" does not implement IOCommand`)},
+		{name: "Wrapped Not IOCommand", pipelines: [][]string{{"lines"}, {"hidden"}}, err: errors.New(`command "hidden" does not implement IOCommand`)},
+		{name: "Wrapped", pipelines: [][]string{{"lines", "-n", "4"}, {"grouped"}}, out: "4\n"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			c := &sub.Commander{IO: sub.IO{Out: &out, Err: ioutil.Discard}, Silent: true}
+			c.SetName("subtest")
+			c.RegisterAll(
+				&linesCmd{},
+				&countCmd{},
+				&ioCmd{},
+				sub.Func("plain", "", "", nil, nil),
+				sub.Hidden(sub.Func("hidden", "", "", nil, nil)),
+				sub.WithGroup(&renamedCountCmd{}, "Counting"),
+				&failCmd{err: fail},
+			)
+
+			err := c.RunPipe(test.pipelines...)
+			if fmt.Sprint(err) != fmt.Sprint(test.err) {
+				t.Errorf("Expected:\t%v", test.err)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if out.String() != test.out {
+				t.Errorf("Expected:\t%q", test.out)
+				t.Errorf("Got:\t\t%q", out.String())
+			}
+		})
+	}
+}
+
+type renamedCountCmd struct {
+	countCmd
+}
+
+func (cmd *renamedCountCmd) Name() string {
+	return "grouped"
+}
+
+type failCmd struct {
+	io  sub.IO
+	err error
+}
+
+func (cmd *failCmd) Name() string {
+	return "fail"
+}
+
+func (cmd *failCmd) Desc() string {
+	return "fail immediately"
+}
+
+func (cmd *failCmd) Help() string {
+	return "Usage: fail"
+}
+
+func (cmd *failCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *failCmd) SetIO(io sub.IO) {
+	cmd.io = io
+}
+
+func (cmd *failCmd) Run(args []string) error {
+	return cmd.err
+}
@@ -0,0 +1,174 @@
+package sub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PluginDescFlag is the flag that plugins found by DiscoverPlugins are
+// run with to get their descriptions. A plugin should respond to it by
+// printing a short description of itself to stdout and exiting.
+const PluginDescFlag = "--sub-plugin-desc"
+
+// PluginCommand is a Command that runs an external executable. Unlike
+// other commands, its arguments, including any flags and "--", are not
+// parsed, but passed to the executable as is. The executable is
+// connected to the Commander's IO.
+type PluginCommand struct {
+	path string
+	name string
+	desc string
+	io   IO
+}
+
+// pluginOf returns cmd as a *PluginCommand, looking through the
+// package's wrappers, or nil if it isn't one.
+func pluginOf(cmd Command) *PluginCommand {
+	if w, ok := cmd.(interface{ plugin() *PluginCommand }); ok {
+		return w.plugin()
+	}
+	plugin, _ := cmd.(*PluginCommand)
+	return plugin
+}
+
+// NewPluginCommand returns a PluginCommand that runs the executable at
+// path under the given name. Its description is fetched by running the
+// executable with PluginDescFlag.
+func NewPluginCommand(path, name string) *PluginCommand {
+	return &PluginCommand{
+		path: path,
+		name: name,
+		desc: pluginDesc(path),
+	}
+}
+
+// pluginDesc returns the description printed by the plugin at path.
+func pluginDesc(path string) string {
+	out, err := exec.Command(path, PluginDescFlag).Output()
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(out))
+}
+
+// Path returns the path of the executable that cmd runs.
+func (cmd *PluginCommand) Path() string {
+	return cmd.path
+}
+
+func (cmd *PluginCommand) Name() string {
+	return cmd.name
+}
+
+func (cmd *PluginCommand) Desc() string {
+	return cmd.desc
+}
+
+func (cmd *PluginCommand) Help() string {
+	return fmt.Sprintf(`Usage: %v [arguments]
+
+%v is a plugin provided by %v.`, cmd.name, cmd.name, cmd.path)
+}
+
+func (cmd *PluginCommand) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *PluginCommand) SetIO(io IO) {
+	cmd.io = io
+}
+
+func (cmd *PluginCommand) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+// RunContext runs the executable with args. If the executable exits
+// with a non-zero status, an *ExitError with that status and no
+// underlying error is returned, as the executable is expected to have
+// reported the problem itself.
+func (cmd *PluginCommand) RunContext(ctx context.Context, args []string) error {
+	return runExecutable(ctx, cmd.path, args, cmd.io)
+}
+
+// runExecutable runs the executable at path with args, connected to
+// io. A non-zero exit status is returned as an *ExitError.
+func runExecutable(ctx context.Context, path string, args []string, io IO) error {
+	p := exec.CommandContext(ctx, path, args...)
+	p.Stdin = io.In
+	p.Stdout = io.Out
+	p.Stderr = io.Err
+
+	err := p.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return &ExitError{Code: exit.ExitCode()}
+	}
+	return err
+}
+
+// DiscoverPlugins searches the directories in the PATH environment
+// variable for executables whose names start with prefix followed by a
+// hyphen, such as "prog-deploy" for the prefix "prog", and registers a
+// PluginCommand for each, named after the rest of the executable's
+// name. As with exec.LookPath, executables in earlier directories take
+// precedence. Plugins never replace commands that are already
+// registered.
+//
+// Directories that can't be read are skipped. If any could not be read
+// for a reason other than not existing, the first such error is
+// returned after all of the plugins that could be found have been
+// registered.
+func (c *Commander) DiscoverPlugins(prefix string) error {
+	prefix += "-"
+
+	var rerr error
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) && (rerr == nil) {
+				rerr = err
+			}
+			continue
+		}
+
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name(), prefix) || !isExecutable(file) {
+				continue
+			}
+
+			name := strings.TrimSuffix(strings.TrimPrefix(file.Name(), prefix), ".exe")
+			if (name == "") || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			if c.Has(name) {
+				continue
+			}
+			c.Register(NewPluginCommand(filepath.Join(dir, file.Name()), name))
+		}
+	}
+
+	return rerr
+}
+
+// isExecutable returns true if file is a regular file that can be
+// executed by someone.
+func isExecutable(file os.FileInfo) bool {
+	if !file.Mode().IsRegular() {
+		return false
+	}
+	return (file.Mode().Perm()&0111 != 0) || strings.HasSuffix(file.Name(), ".exe")
+}
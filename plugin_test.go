@@ -0,0 +1,107 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+const pluginScript = `#!/bin/sh
+if [ "$1" = "--sub-plugin-desc" ]; then
+	echo "a test plugin"
+	exit 0
+fi
+read input
+echo "args: $* input: $input"
+exit 3
+`
+
+func pluginDir(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin tests require a POSIX shell")
+	}
+
+	dir, err := ioutil.TempDir("", "sub-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "subtest-deploy"), []byte(pluginScript), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "subtest-noexec"), []byte(pluginScript), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "subtest-test"), []byte(pluginScript), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "other-tool"), []byte(pluginScript), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir := pluginDir(t)
+	defer os.RemoveAll(dir)
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", filepath.Join(dir, "missing")+string(filepath.ListSeparator)+dir)
+
+	var out bytes.Buffer
+	c := &sub.Commander{IO: sub.IO{In: strings.NewReader("hello\n"), Out: &out}}
+	c.Register(&testCmd{})
+
+	err := c.DiscoverPlugins("subtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"deploy", "test"}
+	if names := commandNames(c); strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+	if _, ok := c.Lookup("test").(*sub.PluginCommand); ok {
+		t.Errorf("Plugin replaced an existing command")
+	}
+
+	plugin, ok := c.Lookup("deploy").(*sub.PluginCommand)
+	if !ok {
+		t.Fatalf("deploy is not a plugin: %#v", c.Lookup("deploy"))
+	}
+	if desc := plugin.Desc(); desc != "a test plugin" {
+		t.Errorf("Expected:\t%q", "a test plugin")
+		t.Errorf("Got:\t\t%q", desc)
+	}
+	if p := plugin.Path(); p != filepath.Join(dir, "subtest-deploy") {
+		t.Errorf("Expected:\t%q", filepath.Join(dir, "subtest-deploy"))
+		t.Errorf("Got:\t\t%q", p)
+	}
+
+	err = c.Run([]string{"subtest", "deploy", "-env", "prod", "--", "now"})
+	var exit *sub.ExitError
+	if !errors.As(err, &exit) || (exit.Code != 3) {
+		t.Errorf("Expected:\t%v", &sub.ExitError{Code: 3})
+		t.Errorf("Got:\t\t%v", err)
+	}
+	if want := "args: -env prod -- now input: hello\n"; out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+}
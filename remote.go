@@ -0,0 +1,164 @@
+package sub
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PluginFlagsFlag is the flag that remote commands are run with to get
+// the definitions of their flags. A remote command should respond to
+// it by printing a JSON array of objects, one per flag, to stdout and
+// exiting. Each object has the following fields:
+//
+//    name     the name of the flag, without any leading hyphens
+//    type     one of bool, string, int, float, or duration
+//    default  the default value of the flag as a string
+//    usage    the usage message of the flag
+//
+// A missing type is treated as string.
+const PluginFlagsFlag = "--sub-plugin-flags"
+
+// remoteFlag is the definition of a single flag of a remote command.
+type remoteFlag struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+}
+
+type remoteCmd struct {
+	path string
+	name string
+	desc string
+	help string
+	io   IO
+
+	once  sync.Once
+	flags []remoteFlag
+}
+
+// NewRemoteCommand returns a Command that runs the executable at path.
+// Unlike a PluginCommand, its flags are parsed by the Commander like
+// any other command's. They are defined by the executable itself, as
+// described by PluginFlagsFlag, which is run the first time that they
+// are needed. If that fails, the command has no flags.
+//
+// When the command is run, the executable is run with the flags that
+// were set, followed by the command's arguments, and is connected to
+// the Commander's IO. A non-zero exit status is returned as an
+// *ExitError.
+func NewRemoteCommand(path string, name, desc, helpText string) Command {
+	return &remoteCmd{
+		path: path,
+		name: name,
+		desc: desc,
+		help: helpText,
+	}
+}
+
+func (cmd *remoteCmd) Name() string {
+	return cmd.name
+}
+
+func (cmd *remoteCmd) Desc() string {
+	return cmd.desc
+}
+
+func (cmd *remoteCmd) Help() string {
+	return cmd.help
+}
+
+// remoteFlags returns the definitions of the flags of cmd, fetching
+// them from the executable if it hasn't been done yet.
+func (cmd *remoteCmd) remoteFlags() []remoteFlag {
+	cmd.once.Do(func() {
+		out, err := exec.Command(cmd.path, PluginFlagsFlag).Output()
+		if err != nil {
+			return
+		}
+		_ = json.Unmarshal(out, &cmd.flags)
+	})
+	return cmd.flags
+}
+
+func (cmd *remoteCmd) Flags(fset *flag.FlagSet) {
+	for _, f := range cmd.remoteFlags() {
+		v := &remoteValue{typ: f.Type}
+		if f.Default != "" {
+			_ = v.Set(f.Default)
+		}
+		fset.Var(v, f.Name, f.Usage)
+	}
+}
+
+func (cmd *remoteCmd) SetIO(io IO) {
+	cmd.io = io
+}
+
+func (cmd *remoteCmd) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+func (cmd *remoteCmd) RunContext(ctx context.Context, args []string) error {
+	var argv []string
+	flagSetFor(ctx, cmd).Visit(func(f *flag.Flag) {
+		argv = append(argv, fmt.Sprintf("-%v=%v", f.Name, f.Value))
+	})
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			argv = append(argv, "--")
+			break
+		}
+	}
+	argv = append(argv, args...)
+
+	return runExecutable(ctx, cmd.path, argv, cmd.io)
+}
+
+// remoteValue is the flag.Value of a remote command's flag. It checks
+// that values are valid for the flag's type, but stores them as is.
+type remoteValue struct {
+	typ   string
+	value string
+}
+
+func (v *remoteValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *remoteValue) Set(str string) error {
+	var err error
+	switch v.typ {
+	case "bool":
+		_, err = strconv.ParseBool(str)
+	case "int":
+		_, err = strconv.ParseInt(str, 0, 64)
+	case "float":
+		_, err = strconv.ParseFloat(str, 64)
+	case "duration":
+		_, err = time.ParseDuration(str)
+	case "", "string":
+	default:
+		return fmt.Errorf("unknown flag type %q", v.typ)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %v value %q", v.typ, str)
+	}
+
+	v.value = str
+	return nil
+}
+
+func (v *remoteValue) IsBoolFlag() bool {
+	return v.typ == "bool"
+}
@@ -0,0 +1,130 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+// remoteEnv is set in the environment of the test binary when it is run
+// as a mock remote command.
+const remoteEnv = "SUB_TEST_REMOTE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(remoteEnv) == "1" {
+		os.Exit(mockRemote(os.Args[1:]))
+	}
+
+	os.Exit(m.Run())
+}
+
+// mockRemote implements the remote command used by the tests in this
+// file.
+func mockRemote(args []string) int {
+	if (len(args) == 1) && (args[0] == sub.PluginFlagsFlag) {
+		fmt.Print(`[
+			{"name": "env", "default": "dev", "usage": "environment to use"},
+			{"name": "v", "type": "bool", "usage": "verbose output"},
+			{"name": "n", "type": "int", "default": "1", "usage": "number of times"}
+		]`)
+		return 0
+	}
+
+	fmt.Printf("%q", args)
+	if (len(args) > 0) && (args[len(args)-1] == "fail") {
+		return 4
+	}
+	return 0
+}
+
+func newRemoteCommander(t *testing.T, out *bytes.Buffer) *sub.Commander {
+	t.Helper()
+
+	os.Setenv(remoteEnv, "1")
+	t.Cleanup(func() { os.Unsetenv(remoteEnv) })
+
+	c := &sub.Commander{IO: sub.IO{Out: out, Err: out}}
+	c.Register(c.HelpCmd())
+	c.Register(sub.NewRemoteCommand(os.Args[0], "remote", "a remote command", "Usage: remote [options] [args]"))
+	return c
+}
+
+func TestRemoteCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		out  string
+	}{
+		{name: "No Flags", args: []string{"a", "b"}, out: `["a" "b"]`},
+		{name: "Flags", args: []string{"-v", "-env", "prod", "a"}, out: `["-env=prod" "-v=true" "a"]`},
+		{name: "Flag-Like Args", args: []string{"-n", "2", "--", "-x"}, out: `["-n=2" "--" "-x"]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := newRemoteCommander(t, &out)
+
+			err := c.Run(append([]string{"subtest", "remote"}, test.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.out {
+				t.Errorf("Expected:\t%v", test.out)
+				t.Errorf("Got:\t\t%v", out.String())
+			}
+		})
+	}
+}
+
+func TestRemoteCommandInvalidFlag(t *testing.T) {
+	var out bytes.Buffer
+	c := newRemoteCommander(t, &out)
+	c.Silent = true
+
+	err := c.Run([]string{"subtest", "remote", "-n", "many"})
+	var parse *sub.FlagParseError
+	if !errors.As(err, &parse) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRemoteCommandExitCode(t *testing.T) {
+	var out bytes.Buffer
+	c := newRemoteCommander(t, &out)
+
+	err := c.Run([]string{"subtest", "remote", "fail"})
+	if code := sub.ExitCode(err); code != 4 {
+		t.Errorf("Expected:\t%v", 4)
+		t.Errorf("Got:\t\t%v (%v)", code, err)
+	}
+}
+
+func TestRemoteCommandHelp(t *testing.T) {
+	var out bytes.Buffer
+	c := newRemoteCommander(t, &out)
+
+	help, err := c.CommandHelpString("remote")
+	if err != flag.ErrHelp {
+		t.Fatal(err)
+	}
+
+	want := `Usage: remote [options] [args]
+
+Options:
+  -env value
+    	environment to use (default dev)
+  -n value
+    	number of times (default 1)
+  -v	verbose output
+`
+	if help != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", help)
+	}
+}
@@ -0,0 +1,73 @@
+package sub
+
+import "io"
+
+// HelpRenderer renders the text output of a Commander's help command.
+// It can be set as a Commander's HelpRenderer to completely replace
+// the built-in formatting.
+type HelpRenderer interface {
+	// RenderGlobalHelp writes the help summary of c, as shown when the
+	// help command is run without arguments, to w.
+	RenderGlobalHelp(w io.Writer, c *Commander)
+
+	// RenderCommandHelp writes the help of cmd, as shown when the help
+	// command is run with its name as an argument, to w. flagDefaults
+	// is the description of cmd's flags, including any persistent
+	// flags, as printed by flag.FlagSet.PrintDefaults.
+	RenderCommandHelp(w io.Writer, cmd Command, flagDefaults string)
+}
+
+// textRenderer is the built-in HelpRenderer. If h is not nil, its
+// Commander and flags are used to determine the styling and which
+// commands are listed.
+type textRenderer struct {
+	h *helpCmd
+}
+
+// DefaultHelpRenderer returns the HelpRenderer used when a Commander's
+// HelpRenderer is nil. Because RenderCommandHelp is not given the
+// Commander, the returned renderer never styles command help, even if
+// it is written to a terminal.
+func DefaultHelpRenderer() HelpRenderer {
+	return textRenderer{}
+}
+
+func (r textRenderer) RenderGlobalHelp(w io.Writer, c *Commander) {
+	h := &helpCmd{Commander: c, out: w}
+	if r.h != nil {
+		h.all = r.h.all
+		h.long = r.h.long
+	}
+	h.textSummary()
+}
+
+func (r textRenderer) RenderCommandHelp(w io.Writer, cmd Command, flagDefaults string) {
+	h := &helpCmd{Commander: &Commander{NoColor: true}, out: w}
+	if r.h != nil {
+		h = r.h.clone(r.h.Commander)
+		h.out = w
+	}
+	h.textCommand(cmd, flagDefaults)
+}
+
+func (c *Commander) helpRenderer() HelpRenderer {
+	if c.HelpRenderer == nil {
+		if c.parent != nil {
+			return c.parent.helpRenderer()
+		}
+		return nil
+	}
+
+	return c.HelpRenderer
+}
+
+// renderer returns the HelpRenderer that h should use.
+func (h *helpCmd) renderer() HelpRenderer {
+	if r := h.helpRenderer(); r != nil {
+		return r
+	}
+	if h.HelpTemplate != "" {
+		return templateRenderer{textRenderer: textRenderer{h: h}, text: h.HelpTemplate}
+	}
+	return textRenderer{h: h}
+}
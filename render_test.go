@@ -0,0 +1,101 @@
+package sub_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) RenderGlobalHelp(w io.Writer, c *sub.Commander) {
+	var cmds []map[string]string
+	for _, cmd := range c.Commands() {
+		cmds = append(cmds, map[string]string{"name": cmd.Name(), "desc": cmd.Desc()})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"commands": cmds})
+}
+
+func (jsonRenderer) RenderCommandHelp(w io.Writer, cmd sub.Command, flagDefaults string) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":  cmd.Name(),
+		"flags": flagDefaults,
+	})
+}
+
+func TestHelpRenderer(t *testing.T) {
+	var out bytes.Buffer
+	c := &sub.Commander{Output: &out, HelpRenderer: jsonRenderer{}}
+	c.Register(c.HelpCmd())
+	c.Register(&testCmd{})
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summary struct {
+		Commands []map[string]string `json:"commands"`
+	}
+	err = json.Unmarshal(out.Bytes(), &summary)
+	if err != nil {
+		t.Fatalf("%v: %q", err, out.String())
+	}
+	want := []map[string]string{
+		{"name": "help", "desc": "show help for commands"},
+		{"name": "test", "desc": "a simple test"},
+	}
+	if !reflect.DeepEqual(summary.Commands, want) {
+		t.Errorf("Expected:\t%v", want)
+		t.Errorf("Got:\t\t%v", summary.Commands)
+	}
+
+	out.Reset()
+	err = c.Run([]string{"subtest", "help", "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var help map[string]string
+	err = json.Unmarshal(out.Bytes(), &help)
+	if err != nil {
+		t.Fatalf("%v: %q", err, out.String())
+	}
+	wantHelp := map[string]string{
+		"name":  "test",
+		"flags": "  -flag string\n    \ta flag test (default \"test\")\n",
+	}
+	if !reflect.DeepEqual(help, wantHelp) {
+		t.Errorf("Expected:\t%q", wantHelp)
+		t.Errorf("Got:\t\t%q", help)
+	}
+}
+
+func TestDefaultHelpRenderer(t *testing.T) {
+	c := sub.NewCommander(sub.WithName("subtest"), sub.WithHelp("Some help."))
+	c.Register(c.HelpCmd())
+	c.Register(&testCmd{})
+
+	var out bytes.Buffer
+	sub.DefaultHelpRenderer().RenderGlobalHelp(&out, c)
+	if want := c.HelpString(); out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+
+	out.Reset()
+	sub.DefaultHelpRenderer().RenderCommandHelp(&out, &testCmd{}, "  -flag string\n    \ta flag test (default \"test\")\n")
+	want, err := c.CommandHelpString("test")
+	if err != flag.ErrHelp {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+}
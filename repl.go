@@ -0,0 +1,41 @@
+package sub
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// REPL reads lines from r and runs each as a separate command line,
+// split into arguments with strings.Fields and prefixed with c's name.
+// Empty lines are skipped. If c's output is a terminal, c.Prompt is
+// printed before each line is read.
+//
+// Errors from running the lines are printed, unless c has already
+// reported them, and do not stop the loop. REPL returns nil once r is
+// exhausted, or an error if reading from it fails.
+func (c *Commander) REPL(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for {
+		if (c.Prompt != "") && isTerminal(c.output()) {
+			fmt.Fprint(c.output(), c.Prompt)
+		}
+		if !s.Scan() {
+			return s.Err()
+		}
+
+		args := strings.Fields(s.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		err := c.Run(append([]string{c.progName()}, args...))
+		switch {
+		case (err == nil) || (err == flag.ErrHelp) || (err == ErrVersion):
+		case (c.ErrorHandler == nil) && !c.reported(err):
+			c.PrintError(err)
+		}
+	}
+}
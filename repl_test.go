@@ -0,0 +1,67 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func newREPLCommander(out io.Writer, ran *[]string) *sub.Commander {
+	c := &sub.Commander{Output: out, Prompt: "> "}
+	c.SetName("subtest")
+	c.Register(c.HelpCmd())
+	c.Register(sub.Func("echo", "", "", nil, func(args []string) error {
+		*ran = append(*ran, strings.Join(args, " "))
+		return nil
+	}))
+	c.Register(sub.Func("fail", "", "", nil, func([]string) error {
+		return errors.New("failed")
+	}))
+	return c
+}
+
+func TestREPL(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	c := newREPLCommander(&out, &ran)
+
+	err := c.REPL(strings.NewReader("echo a b\n\n  echo   c  \nfail\nhelp echo\necho d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a b", "c", "d"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", ran)
+	}
+	if !strings.Contains(out.String(), "Error: failed\n") {
+		t.Errorf("Error not printed: %q", out.String())
+	}
+	if strings.Contains(out.String(), "> ") {
+		t.Errorf("Prompt printed to non-terminal: %q", out.String())
+	}
+}
+
+func TestREPLPrompt(t *testing.T) {
+	restore := sub.SetIsTerminal(func(io.Writer) bool { return true })
+	defer restore()
+
+	var out bytes.Buffer
+	var ran []string
+	c := newREPLCommander(&out, &ran)
+
+	err := c.REPL(strings.NewReader("echo a\necho b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "> > > "; out.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out.String())
+	}
+}
@@ -0,0 +1,123 @@
+package sub
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// timeout returns the Timeout of c or, if it has none, of its nearest
+// parent that does.
+func (c *Commander) timeout() time.Duration {
+	if (c.Timeout == 0) && (c.parent != nil) {
+		return c.parent.timeout()
+	}
+	return c.Timeout
+}
+
+// recovers returns true if c or any of its parents have Recover set.
+func (c *Commander) recovers() bool {
+	return c.Recover || ((c.parent != nil) && c.parent.recovers())
+}
+
+// run runs cmd, subject to c's Timeout and Recover fields.
+func (c *Commander) run(ctx context.Context, cmd Command, args []string) error {
+	d := c.timeout()
+	if d <= 0 {
+		return c.call(ctx, cmd, args)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	// The channel is buffered so that the goroutine can exit once the
+	// command returns even if the timeout has already been reached.
+	done := make(chan runResult, 1)
+	go func() {
+		// A panic can only be recovered by the goroutine that it happens
+		// in, so it is caught here and handed back to be raised again in
+		// the caller's goroutine, where middleware such as the one
+		// returned by RecoverMiddleware can recover it. If the timeout
+		// has already been reached, nothing is waiting for it, and it is
+		// dropped instead of crashing the program.
+		panicked := true
+		defer func() {
+			if panicked {
+				done <- runResult{panicked: true, value: recover()}
+			}
+		}()
+
+		err := c.call(ctx, cmd, args)
+		panicked = false
+		done <- runResult{err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.panicked {
+			panic(r.value)
+		}
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runResult is the result of running a command in its own goroutine.
+type runResult struct {
+	err      error
+	panicked bool
+	value    interface{}
+}
+
+// call runs cmd, recovering from any panic if c recovers. It must be
+// called from the goroutine that runs cmd for recover to work.
+func (c *Commander) call(ctx context.Context, cmd Command, args []string) (err error) {
+	if c.recovers() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+
+	err = runCommand(ctx, cmd, args)
+	if err == nil {
+		c.count(cmd.Name())
+	}
+	return err
+}
+
+// count increments the number of times that the named command has run
+// successfully.
+func (c *Commander) count(name string) {
+	n, ok := c.stats.Load(name)
+	if !ok {
+		n, _ = c.stats.LoadOrStore(name, new(int64))
+	}
+	atomic.AddInt64(n.(*int64), 1)
+}
+
+// Stats returns a snapshot of the number of times that each command
+// has been run successfully by c since it was created or since the
+// last call to ResetStats, keyed by the commands' names. Commands that
+// have never run successfully are not included. Runs of the commands
+// of nested Commanders are counted by the nested Commanders rather
+// than by c.
+func (c *Commander) Stats() map[string]int {
+	stats := make(map[string]int)
+	c.stats.Range(func(name, n interface{}) bool {
+		stats[name.(string)] = int(atomic.LoadInt64(n.(*int64)))
+		return true
+	})
+	return stats
+}
+
+// ResetStats clears the counts returned by Stats.
+func (c *Commander) ResetStats() {
+	c.stats.Range(func(name, n interface{}) bool {
+		c.stats.Delete(name)
+		return true
+	})
+}
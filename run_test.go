@@ -0,0 +1,129 @@
+package sub_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	c := &sub.Commander{Timeout: 10 * time.Millisecond}
+	c.RegisterAll(
+		sub.Func("sleep", "", "", nil, func([]string) error {
+			<-release
+			return nil
+		}),
+		&ctxFuncCmd{run: func(ctx context.Context, args []string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		sub.Func("quick", "", "", nil, func([]string) error { return nil }),
+	)
+
+	for _, name := range []string{"sleep", "ctx"} {
+		start := time.Now()
+		err := c.Run([]string{"subtest", name})
+		if err != context.DeadlineExceeded {
+			t.Errorf("%v: Expected:\t%v", name, context.DeadlineExceeded)
+			t.Errorf("%v: Got:\t\t%v", name, err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("%v: Run took %v", name, d)
+		}
+	}
+
+	err := c.Run([]string{"subtest", "quick"})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	c := &sub.Commander{Recover: true}
+	c.Register(sub.Func("panic", "", "", nil, func([]string) error {
+		panic("oh no")
+	}))
+
+	for _, timeout := range []time.Duration{0, time.Second} {
+		c.Timeout = timeout
+
+		err := c.Run([]string{"subtest", "panic"})
+		var p *sub.PanicError
+		if !errors.As(err, &p) {
+			t.Fatalf("Expected *sub.PanicError, got %#v", err)
+		}
+		if p.Value != "oh no" {
+			t.Errorf("Expected:\t%q", "oh no")
+			t.Errorf("Got:\t\t%q", p.Value)
+		}
+		if len(p.Stack) == 0 {
+			t.Errorf("Stack is empty")
+		}
+		if err.Error() != "panic: oh no" {
+			t.Errorf("Expected:\t%q", "panic: oh no")
+			t.Errorf("Got:\t\t%q", err.Error())
+		}
+	}
+}
+
+func TestTimeoutRecoverMiddleware(t *testing.T) {
+	c := &sub.Commander{Timeout: time.Second}
+	c.Use(sub.RecoverMiddleware())
+	c.Register(sub.Func("panic", "", "", nil, func([]string) error {
+		panic("oh no")
+	}))
+
+	err := c.Run([]string{"subtest", "panic"})
+	if want := `command "panic" panicked: oh no`; (err == nil) || (err.Error() != want) {
+		t.Errorf("Expected:\t%v", want)
+		t.Errorf("Got:\t\t%v", err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	fail := errors.New("failed")
+
+	c := &sub.Commander{}
+	c.RegisterAll(
+		sub.Func("ok", "", "", nil, func([]string) error { return nil }),
+		sub.Func("fail", "", "", nil, func([]string) error { return fail }),
+	)
+
+	err := c.Run([]string{"subtest", "ok"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = c.Run([]string{"subtest", "ok"})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = c.Run([]string{"subtest", "fail"})
+		}()
+	}
+	wg.Wait()
+
+	want := map[string]int{"ok": 11}
+	if stats := c.Stats(); !reflect.DeepEqual(stats, want) {
+		t.Errorf("Expected:\t%v", want)
+		t.Errorf("Got:\t\t%v", stats)
+	}
+
+	c.ResetStats()
+	if stats := c.Stats(); len(stats) != 0 {
+		t.Errorf("Stats not reset: %v", stats)
+	}
+}
@@ -0,0 +1,24 @@
+package sub
+
+// ByName is a Sort function that lists commands by name. This is the
+// same order that is used when a Commander's Sort field is nil.
+func ByName(a, b Command) bool {
+	return a.Name() < b.Name()
+}
+
+// ByGroup is a Sort function that lists commands by group and then by
+// name. Commands without a group come first.
+func ByGroup(a, b Command) bool {
+	ga, gb := groupOf(a), groupOf(b)
+	if ga != gb {
+		return ga < gb
+	}
+	return a.Name() < b.Name()
+}
+
+// ByRegistration is a Sort function that lists commands in the order
+// in which they were registered. Re-registering a command moves it to
+// the end.
+func ByRegistration(a, b Command) bool {
+	return false
+}
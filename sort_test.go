@@ -0,0 +1,154 @@
+package sub_test
+
+import (
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name string
+		sort func(a, b sub.Command) bool
+		help string
+	}{
+		{
+			name: "Default",
+			help: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	alpha  the alpha command
+	help   show help for commands
+	zeta   the zeta command
+
+Other commands:
+	beta   the beta command
+`,
+		},
+		{
+			name: "ByName",
+			sort: sub.ByName,
+			help: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	alpha  the alpha command
+	help   show help for commands
+	zeta   the zeta command
+
+Other commands:
+	beta   the beta command
+`,
+		},
+		{
+			name: "ByRegistration",
+			sort: sub.ByRegistration,
+			help: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	zeta   the zeta command
+	help   show help for commands
+	alpha  the alpha command
+
+Other commands:
+	beta   the beta command
+`,
+		},
+		{
+			name: "Reverse",
+			sort: func(a, b sub.Command) bool { return a.Name() > b.Name() },
+			help: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	zeta   the zeta command
+	help   show help for commands
+	alpha  the alpha command
+
+Other commands:
+	beta   the beta command
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &sub.Commander{Sort: test.sort}
+			c.SetName("subtest")
+			c.RegisterAll(
+				sub.Func("zeta", "the zeta command", "", nil, nil),
+				c.HelpCmd(),
+				sub.WithGroup(sub.Func("beta", "the beta command", "", nil, nil), "Other"),
+				sub.Func("alpha", "the alpha command", "", nil, nil),
+			)
+
+			if help := c.HelpString(); help != test.help {
+				t.Errorf("Expected:\t%q", test.help)
+				t.Errorf("Got:\t\t%q", help)
+			}
+
+			for _, name := range []string{"alpha", "beta", "help", "zeta"} {
+				if !c.Has(name) {
+					t.Errorf("Lookup of %q failed", name)
+				}
+			}
+		})
+	}
+}
+
+func TestByGroup(t *testing.T) {
+	cmds := []sub.Command{
+		sub.WithGroup(sub.Func("b", "", "", nil, nil), "X"),
+		sub.WithGroup(sub.Func("a", "", "", nil, nil), "X"),
+		sub.Func("c", "", "", nil, nil),
+	}
+
+	tests := []struct {
+		a, b int
+		want bool
+	}{
+		{a: 1, b: 0, want: true},
+		{a: 0, b: 1, want: false},
+		{a: 2, b: 0, want: true},
+		{a: 0, b: 2, want: false},
+	}
+
+	for _, test := range tests {
+		if got := sub.ByGroup(cmds[test.a], cmds[test.b]); got != test.want {
+			t.Errorf("ByGroup(%v, %v): Expected %v, got %v", cmds[test.a].Name(), cmds[test.b].Name(), test.want, got)
+		}
+	}
+}
+
+func TestWithPriority(t *testing.T) {
+	c := &sub.Commander{}
+	c.SetName("subtest")
+	c.RegisterAll(
+		sub.Func("alpha", "the alpha command", "", nil, nil),
+		sub.WithPriority(c.HelpCmd(), -1),
+		sub.WithPriority(sub.Func("beta", "the beta command", "", nil, nil), 1),
+		sub.Func("zeta", "the zeta command", "", nil, nil),
+	)
+
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help   show help for commands
+	alpha  the alpha command
+	zeta   the zeta command
+	beta   the beta command
+`
+	for _, sort := range []func(a, b sub.Command) bool{nil, sub.ByName} {
+		c.Sort = sort
+		if help := c.HelpString(); help != want {
+			t.Errorf("Expected:\t%q", want)
+			t.Errorf("Got:\t\t%q", help)
+		}
+	}
+
+	prioritized, ok := c.Lookup("help").(sub.PrioritizedCommand)
+	if !ok || (prioritized.Priority() != -1) {
+		t.Errorf("Priority was not set")
+	}
+}
@@ -0,0 +1,165 @@
+package sub
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterStruct registers commands with c based on the fields of v,
+// which must be a struct or a pointer to one, in a similar way to how
+// encoding/json uses struct tags.
+//
+// Each field with a sub tag must hold a Command, which is registered.
+// The tag has the form "name,desc". If either part is non-empty, it
+// overrides the command's own name or description, respectively:
+//
+//    type Commands struct {
+//    	List  *ListCmd  `sub:""`
+//    	Del   *RmCmd    `sub:"del,delete a thing"`
+//    }
+//
+// v itself can also be registered as a command by giving it a blank
+// field with a sub tag, which provides the command's name and
+// description, and a Run([]string) error method. If it also has
+// Help() string or Flags(*flag.FlagSet) methods, they are used as
+// well:
+//
+//    type Deploy struct {
+//    	_ struct{} `sub:"deploy,deploy the application"`
+//    }
+//
+//    func (d *Deploy) Run(args []string) error { ... }
+//
+// Fields that can't be registered, such as those that don't hold a
+// Command or whose names duplicate those of other fields, are skipped,
+// and an error listing them is returned after the rest have been
+// registered.
+func RegisterStruct(c *Commander, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() == reflect.Ptr) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("sub: RegisterStruct of non-struct type %T", v)
+	}
+	rt := rv.Type()
+
+	var problems []string
+	seen := make(map[string]string)
+	register := func(field string, cmd Command) {
+		if other, ok := seen[cmd.Name()]; ok {
+			problems = append(problems, fmt.Sprintf("field %v: duplicate name %q, also used by field %v", field, cmd.Name(), other))
+			return
+		}
+		seen[cmd.Name()] = field
+		c.Register(cmd)
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		tag, ok := f.Tag.Lookup("sub")
+		if !ok {
+			continue
+		}
+		name, desc := parseTag(tag)
+
+		if f.Name == "_" {
+			cmd, err := structCommand(v, name, desc)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("field _: %v", err))
+				continue
+			}
+			register(f.Name, cmd)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			problems = append(problems, fmt.Sprintf("field %v: unexported", f.Name))
+			continue
+		}
+
+		fv := rv.Field(i)
+		cmd, ok := fv.Interface().(Command)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field %v: type %v does not implement Command", f.Name, f.Type))
+			continue
+		}
+		if ((fv.Kind() == reflect.Ptr) || (fv.Kind() == reflect.Interface)) && fv.IsNil() {
+			problems = append(problems, fmt.Sprintf("field %v: nil", f.Name))
+			continue
+		}
+
+		if (name != "") || (desc != "") {
+			cmd = namedCmd{wrapper: wrapper{cmd}, name: name, desc: desc}
+		}
+		register(f.Name, cmd)
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("sub: could not register %v", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// parseTag splits a sub struct tag into its name and description.
+func parseTag(tag string) (name, desc string) {
+	parts := strings.SplitN(tag, ",", 2)
+	if len(parts) == 2 {
+		desc = parts[1]
+	}
+	return parts[0], desc
+}
+
+// structCommand returns a Command built from the methods of v.
+func structCommand(v interface{}, name, desc string) (Command, error) {
+	if name == "" {
+		return nil, errors.New("missing command name")
+	}
+
+	runner, ok := v.(interface{ Run([]string) error })
+	if !ok {
+		return nil, fmt.Errorf("type %T does not have a Run([]string) error method", v)
+	}
+
+	var help string
+	if helper, ok := v.(interface{ Help() string }); ok {
+		help = helper.Help()
+	}
+
+	var flags func(*flag.FlagSet)
+	if flagger, ok := v.(interface{ Flags(*flag.FlagSet) }); ok {
+		flags = flagger.Flags
+	}
+
+	return Func(name, desc, help, flags, runner.Run), nil
+}
+
+// namedCmd overrides the name and description of a Command. Empty
+// values leave the wrapped Command's name or description as is.
+type namedCmd struct {
+	wrapper
+	name string
+	desc string
+}
+
+func (cmd namedCmd) Name() string {
+	if cmd.name == "" {
+		return cmd.Command.Name()
+	}
+	return cmd.name
+}
+
+func (cmd namedCmd) Desc() string {
+	if cmd.desc == "" {
+		return cmd.Command.Desc()
+	}
+	return cmd.desc
+}
+
+func (cmd namedCmd) rewrap(inner Command) Command {
+	cmd.Command = inner
+	return cmd
+}
@@ -0,0 +1,140 @@
+package sub_test
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+type deployCmd struct {
+	_ struct{} `sub:"deploy,deploy the application"`
+
+	env  string
+	args []string
+}
+
+func (cmd *deployCmd) Help() string {
+	return "Usage: deploy [options]"
+}
+
+func (cmd *deployCmd) Flags(fset *flag.FlagSet) {
+	fset.StringVar(&cmd.env, "env", "dev", "environment to deploy to")
+}
+
+func (cmd *deployCmd) Run(args []string) error {
+	cmd.args = args
+	return nil
+}
+
+func TestRegisterStruct(t *testing.T) {
+	var ran bool
+	cmds := struct {
+		Test    *testCmd    `sub:""`
+		Rm      *aliasedCmd `sub:"del,delete a thing"`
+		Ignored *testCmd
+	}{
+		Test: &testCmd{},
+		Rm:   &aliasedCmd{ran: &ran},
+	}
+
+	var c sub.Commander
+	err := sub.RegisterStruct(&c, &cmds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"del", "test"}
+	if names := commandNames(&c); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+	if desc := c.Lookup("del").Desc(); desc != "delete a thing" {
+		t.Errorf("Expected:\t%q", "delete a thing")
+		t.Errorf("Got:\t\t%q", desc)
+	}
+
+	err = c.Run([]string{"subtest", "remove"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Errorf("Command not run by alias")
+	}
+}
+
+func TestRegisterStructSelf(t *testing.T) {
+	cmd := &deployCmd{}
+
+	var c sub.Commander
+	err := sub.RegisterStruct(&c, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := c.Lookup("deploy")
+	if registered == nil {
+		t.Fatal("deploy not registered")
+	}
+	if desc := registered.Desc(); desc != "deploy the application" {
+		t.Errorf("Expected:\t%q", "deploy the application")
+		t.Errorf("Got:\t\t%q", desc)
+	}
+	if help := registered.Help(); help != "Usage: deploy [options]" {
+		t.Errorf("Expected:\t%q", "Usage: deploy [options]")
+		t.Errorf("Got:\t\t%q", help)
+	}
+
+	err = c.Run([]string{"subtest", "deploy", "-env", "prod", "now"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (cmd.env != "prod") || !reflect.DeepEqual(cmd.args, []string{"now"}) {
+		t.Errorf("Got:\t%q %q", cmd.env, cmd.args)
+	}
+}
+
+func TestRegisterStructErrors(t *testing.T) {
+	cmds := struct {
+		_        struct{}    `sub:"self"`
+		Test     *testCmd    `sub:""`
+		Other    *testCmd    `sub:""`
+		NotCmd   string      `sub:"notcmd"`
+		Nil      *testCmd    `sub:"nil"`
+		unexport *testCmd    `sub:"unexported"`
+		Rm       *aliasedCmd `sub:""`
+	}{
+		Test:     &testCmd{},
+		Other:    &testCmd{},
+		unexport: &testCmd{},
+		Rm:       &aliasedCmd{},
+	}
+
+	var c sub.Commander
+	err := sub.RegisterStruct(&c, &cmds)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	for _, field := range []string{"_", "Other", "NotCmd", "Nil", "unexport"} {
+		if !strings.Contains(err.Error(), "field "+field+":") {
+			t.Errorf("Error doesn't mention field %v: %v", field, err)
+		}
+	}
+
+	want := []string{"rm", "test"}
+	if names := commandNames(&c); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+}
+
+func TestRegisterStructNonStruct(t *testing.T) {
+	var c sub.Commander
+	err := sub.RegisterStruct(&c, 3)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
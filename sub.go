@@ -23,16 +23,48 @@
 //      fmt.Fprintf(os.Stderr, "Error: %v", err)
 //      os.Exit(1)
 //    }
+//
+// Small programs can skip creating a Commander entirely by using the
+// package-level functions, such as Register and Run, which use
+// DefaultCommander.
+//
+// Concurrency
+//
+// The set of commands registered with a Commander is guarded by a
+// read-write lock, so commands can be registered and unregistered
+// from multiple goroutines, including concurrently with calls to Run.
+// Methods that read the set, such as Lookup, Commands, and Walk, take
+// a consistent snapshot of it, and Run looks its command up under the
+// read lock but does not hold it while the command runs, so commands
+// are free to register others. Run itself can be called from multiple
+// goroutines at once, as long as the Commander doesn't use output
+// transformations added with UseOutput. The other fields of a
+// Commander are not guarded and should not be modified while it is in
+// use.
+//
+// Programs that never register commands concurrently can build with
+// the nosync tag to remove the locking entirely.
 package sub
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DeedleFake/sub/internal/ansi"
 )
 
 // A Commander controls a set of subcommands.
@@ -41,95 +73,974 @@ type Commander struct {
 	// os.Stderr.
 	Output io.Writer
 
+	// ErrOutput is the location to which error messages, such as the
+	// message printed when a command can't be found or a flag can't be
+	// parsed, and warnings are written, as well as where the
+	// ErrorHandler writes to. This allows them to be separated from
+	// help and other output. Defaults to the same location as the rest
+	// of the output.
+	ErrOutput io.Writer
+
+	// Input is passed to commands that implement InputCommand before
+	// they are run. Defaults to os.Stdin.
+	Input io.Reader
+
+	// IO bundles the streams used by the Commander and passed to
+	// commands that implement IOCommand. Any of its fields that are
+	// set take precedence over Output and Input, with IO.Err being
+	// used in place of Output. ErrOutput takes precedence over IO.Err
+	// for error messages.
+	IO IO
+
 	// Help is text displayed when the help command is run without any
 	// arguments.
 	Help string
 
+	// LongHelp is an extended description of the program that is
+	// displayed below Help when the help command is run with the -long
+	// flag. Like Help, surrounding whitespace is removed before it is
+	// displayed.
+	LongHelp string
+
+	// Header and Footer are text displayed in the help summary
+	// immediately before and after the command listing, respectively.
+	// Like Help, surrounding whitespace is removed before they are
+	// displayed.
+	Header string
+	Footer string
+
 	// Flags is a function that is called to populate the global
 	// FlagSet. If it is non-nil, then it is assumed that there are
 	// global flags, which changes some text formatting.
 	Flags func(*flag.FlagSet)
 
-	name     string
-	commands []Command
+	// PersistentFlags is like Flags, but is called to populate the
+	// FlagSet of every subcommand before the subcommand's own Flags
+	// method is called. Persistent flags are parsed alongside the
+	// subcommand's flags and show up in the subcommand's help. A
+	// subcommand must not define a flag with the same name as a
+	// persistent flag.
+	PersistentFlags func(*flag.FlagSet)
+
+	// AutoVersion, if it is not empty, is the version of the program.
+	// It adds the same -version global flag as SetVersion does, but
+	// does not register a version command. If SetVersion has been
+	// called, the version that it set takes precedence.
+	AutoVersion string
+
+	// Default is the command that is run if no subcommand is given. If
+	// it is nil, the help summary is shown instead. Default should
+	// usually be registered as well so that it can be run explicitly
+	// and so that it shows up in the help summary, where it is marked
+	// as the default.
+	Default Command
+
+	// NotFound, if non-nil, is called instead of printing the built-in
+	// error message and usage when Run is asked to run a command that
+	// doesn't exist. It is passed the Commander's error output and the
+	// name of the command, and its return value is returned from Run.
+	NotFound func(output io.Writer, name string) error
+
+	// OnNoArgs, if non-nil, is called instead of printing usage when
+	// Run is not given a command to run and there is no Default. It is
+	// passed the Commander's output, and its return value is returned
+	// from Run.
+	OnNoArgs func(output io.Writer) error
+
+	// HelpPadding is the minimum number of spaces between the names of
+	// commands and their descriptions in the help listing. If it is
+	// zero, a padding of 2 is used.
+	HelpPadding int
+
+	// MaxWidth is the width, in columns, that command descriptions in
+	// the help listing are wrapped to. If it is zero, the value of the
+	// COLUMNS environment variable is used if it is set, and 80 is
+	// used otherwise.
+	MaxWidth int
+
+	// NoColor disables styling of help output using ANSI escape
+	// sequences. If it is false, help output is styled whenever it is
+	// being written to a terminal, and left unstyled otherwise.
+	NoColor bool
+
+	// HelpRenderer, if it is not nil, renders the text output of the
+	// help command in place of the built-in formatting. If it is nil,
+	// the parent's HelpRenderer is used for nested Commanders, and
+	// DefaultHelpRenderer is used otherwise.
+	HelpRenderer HelpRenderer
+
+	// HelpTemplate, if it is not empty and HelpRenderer is nil, is
+	// parsed as a text/template and used to render the help summary.
+	// See DefaultHelpTemplate for details.
+	HelpTemplate string
+
+	// PreRun, if non-nil, is called after a command's flags have been
+	// parsed but before the command is run. If it returns an error, the
+	// command is not run and the error is returned.
+	PreRun func(cmd Command, args []string) error
+
+	// PostRun, if non-nil, is called after a command has been run,
+	// provided that PreRun didn't fail. It is passed the error returned
+	// by the command, which may be nil, and its own return value is
+	// returned in place of that error.
+	PostRun func(cmd Command, args []string, runErr error) error
+
+	// EnvPrefix, if non-empty, enables setting command flags from
+	// environment variables. After a command's flags are parsed, any
+	// flag that wasn't set on the command line is set from the variable
+	// named EnvPrefix followed by the command name, an underscore, and
+	// the flag name, all upper-cased and with hyphens replaced by
+	// underscores. For example, with a prefix of "APP_", the -api-token
+	// flag of the login command is read from $APP_LOGIN_API_TOKEN. Flags
+	// without a corresponding variable keep their defaults.
+	EnvPrefix string
+
+	// Silent disables the automatic printing of help and error messages
+	// when parsing fails or no command is found, as well as the warnings
+	// printed for deprecated commands. Errors are still returned from
+	// Run. Help that is explicitly requested, either with the help
+	// command or the -h flag, is still printed. Nested Commanders are
+	// silent if their parent is.
+	Silent bool
+
+	// ErrorHandler, if it is not nil, is called with the Commander's
+	// output and any error returned by Run other than flag.ErrHelp and
+	// ErrVersion, just before Run returns it. It can be used to
+	// customize how errors are displayed. PrintError prints errors the
+	// same way as RunOS does. Only the outermost Commander's
+	// ErrorHandler is called for errors from nested Commanders.
+	ErrorHandler func(w io.Writer, err error)
+
+	// ErrorPrefix is printed at the start of error messages, such as
+	// the one printed when a command isn't found. If it is empty, the
+	// parent's prefix is used for nested Commanders, and "Error: "
+	// otherwise.
+	ErrorPrefix string
+
+	// Interspersed allows a command's flags to be mixed in with its
+	// positional arguments, so that, for example, "cmd file.txt -v" sets
+	// the -v flag instead of passing it to the command as an argument.
+	// Only the positional arguments are passed to the command. As
+	// usual, "--" ends flag parsing and everything after it is
+	// positional. Global flags and the completion command are
+	// unaffected.
+	Interspersed bool
+
+	// MinArgs and MaxArgs, if non-zero, limit the number of positional
+	// arguments that can be passed to a command after its flags have
+	// been parsed. If a command is given too few or too many, Run
+	// returns an error describing the problem without running it.
+	// Commands that implement ArgsValidator validate their own
+	// arguments instead, as do the built-in commands returned by
+	// HelpCmd, VersionCmd, and CompletionCmd, to which the limits never
+	// apply. If either is zero, the corresponding field of c's parent,
+	// if any, is used.
+	MinArgs int
+	MaxArgs int
+
+	// AutoHelp, if true, makes Register register the command returned
+	// by HelpCmd whenever no command named "help" is registered. It is
+	// false by default for backwards compatibility, but setting it is
+	// recommended. To use a custom help command instead, register it
+	// under the name "help" and it will replace the automatic one.
+	AutoHelp bool
+
+	// DisableBuiltinHelp, if true, stops Run from displaying anything
+	// on its own when it can't determine which command to run. Flag
+	// errors and explicit requests for help with -h are not printed,
+	// ErrNoCommand is returned instead of showing the help summary if
+	// no command is given, and an *UnknownCommandError is returned
+	// without printing a message if the command isn't registered.
+	// Clients that set it are responsible for displaying all such
+	// errors themselves. It does not affect a registered help command.
+	DisableBuiltinHelp bool
+
+	// Prompt is printed by REPL before reading each line if the output
+	// is a terminal.
+	Prompt string
+
+	// Sort, if non-nil, determines the order in which commands with the
+	// same priority are listed in the help summary. It reports whether
+	// a should be listed before b. Commands that it considers equal are
+	// listed in the order in which they were registered. If Sort is
+	// nil, commands are listed by name. See ByName, ByGroup, and
+	// ByRegistration, as well as PrioritizedCommand.
+	//
+	// Sort only affects how commands are displayed. Lookups are
+	// unaffected by it.
+	Sort func(a, b Command) bool
+
+	// Timeout, if non-zero, limits how long a command can run for. The
+	// command is run with a context that is cancelled once Timeout has
+	// passed, and if it hasn't returned by then, Run returns
+	// context.DeadlineExceeded without waiting for it. Commands that
+	// implement CommandContext should return promptly when their
+	// context is cancelled. A command that panics before the timeout
+	// passes panics in the goroutine that called Run, as it would
+	// without a timeout. If it is zero, the Timeout of c's parent, if
+	// any, is used.
+	Timeout time.Duration
+
+	// Recover, if true, makes Run recover from panics in commands and
+	// return them as a *PanicError instead of crashing the program. If
+	// it is false, the Recover field of c's parent, if any, is used.
+	Recover bool
+
+	// DryRun, if true, makes Run parse and validate its arguments as
+	// usual, but print the command that it would have run instead of
+	// running it. See DryRunner. If it is false, the DryRun field of
+	// c's parent, if any, is used.
+	DryRun bool
+
+	// NormalizeNames, if true, makes hyphens and underscores
+	// interchangeable in the names of commands and flags, so that a
+	// command named my-cmd can also be run as my_cmd, and a flag named
+	// dry-run can also be given as -dry_run. Commands are still
+	// registered and listed under the names that they return from
+	// Name. If it is false, the NormalizeNames field of c's parent, if
+	// any, is used.
+	NormalizeNames bool
+
+	// Abbreviate, if true, lets commands be run by giving any prefix of
+	// their names, or of one of their aliases, that doesn't match any
+	// other command. Run returns an *AmbiguousCommandError if a prefix
+	// matches more than one command. If it is false, the Abbreviate
+	// field of c's parent, if any, is used.
+	Abbreviate bool
+
+	// NameTransform, if not nil, is applied to the names and aliases of
+	// commands when they are registered. Commands are listed in the
+	// help and run under the transformed names, and the names that
+	// they return from Name are unaffected. For example, to namespace
+	// all of c's commands:
+	//
+	//    c.NameTransform = func(name string) string { return "k8s:" + name }
+	//
+	// Commands can also be looked up and run under their untransformed
+	// names. NameTransform should be set before any commands are
+	// registered.
+	NameTransform func(string) string
+
+	// FlagErrorHandling determines what happens when the global flags
+	// or a command's flags can't be parsed, including when help is
+	// requested with -h. With the default, flag.ContinueOnError, Run
+	// returns the error. With flag.ExitOnError, the program exits with
+	// a status of 0 if help was requested and 2 otherwise, and with
+	// flag.PanicOnError, Run panics with the error that it would have
+	// returned. In all cases, the error is reported as usual first. If
+	// it is flag.ContinueOnError, the FlagErrorHandling of c's parent,
+	// if any, is used.
+	FlagErrorHandling flag.ErrorHandling
+
+	// UsageFunc, if non-nil, is called instead of printing the help
+	// summary whenever Run would otherwise print it on its own, such as
+	// when a global flag can't be parsed or no command is given.
+	UsageFunc func()
+
+	// CommandUsageFunc, if non-nil, is called with the command instead
+	// of printing the command's help whenever Run would otherwise print
+	// it on its own, such as when one of its flags can't be parsed. If
+	// it is nil, UsageFunc is used instead if it is non-nil.
+	CommandUsageFunc func(cmd Command)
+
+	name       atomic.Value // string
+	version    string
+	parent     *Commander
+	global     atomic.Value
+	mu         rwMutex
+	seq        uint64
+	commands   []entry
+	middleware []MiddlewareFunc
+	transforms []func(io.Writer) io.Writer
+	runOutput  io.Writer
+	stats      sync.Map
+}
+
+// entry is a single name that a command can be looked up by. Aliased
+// commands have one entry for their canonical name and one for each
+// of their aliases. Entries are kept sorted by name. seq records the
+// order in which commands were registered and is shared by all of a
+// command's entries.
+type entry struct {
+	name string
+	cmd  Command
+	seq  uint64
 }
 
 func (c *Commander) output() io.Writer {
+	if c.runOutput != nil {
+		return c.runOutput
+	}
+
+	if c.IO.Err != nil {
+		return c.IO.Err
+	}
+
 	if c.Output == nil {
+		if c.parent != nil {
+			return c.parent.output()
+		}
 		return os.Stderr
 	}
 
 	return c.Output
 }
 
+// errOutput returns the writer that error messages should be written
+// to.
+func (c *Commander) errOutput() io.Writer {
+	if c.ErrOutput != nil {
+		return c.ErrOutput
+	}
+
+	if (c.Output == nil) && (c.IO.Err == nil) && (c.parent != nil) {
+		return c.parent.errOutput()
+	}
+
+	return c.output()
+}
+
+// SetOutput sets c's Output field. It does nothing if c is nil.
+func (c *Commander) SetOutput(w io.Writer) {
+	if c == nil {
+		return
+	}
+
+	c.Output = w
+}
+
+// GetOutput returns the writer that c actually writes its output to,
+// taking into account the defaults described by the Output and IO
+// fields. If c is nil, it returns os.Stderr.
+func (c *Commander) GetOutput() io.Writer {
+	if c == nil {
+		return os.Stderr
+	}
+
+	return c.output()
+}
+
+func (c *Commander) input() io.Reader {
+	if c.IO.In != nil {
+		return c.IO.In
+	}
+
+	if c.Input == nil {
+		if c.parent != nil {
+			return c.parent.input()
+		}
+		return os.Stdin
+	}
+
+	return c.Input
+}
+
 // Register registers a command with the Commander. If a command with
 // the same name as cmd already exists, it is replaced with cmd.
+//
+// If cmd implements AliasedCommand, it is also registered under each
+// of its aliases, replacing any existing commands with those names.
 func (c *Commander) Register(cmd Command) {
-	for i := range c.commands {
-		if c.commands[i].Name() == cmd.Name() {
-			c.commands[i] = cmd
-			return
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.register(cmd)
+}
+
+// RegisterOnce registers cmd in the same way as Register, but only if
+// there is no command already registered under its name, including as
+// an alias. Unlike Register, it never replaces an existing command. It
+// returns true if cmd was registered.
+func (c *Commander) RegisterOnce(cmd Command) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookup(cmd.Name()) != nil {
+		return false
+	}
+
+	c.register(cmd)
+	return true
+}
+
+// MustRegister registers cmd in the same way as RegisterOnce, but
+// panics if there is already a command registered under its name. The
+// panic message includes the name and the package that MustRegister
+// was called from. It returns cmd so that it can be used to register
+// commands while initializing package-level variables:
+//
+//    var _ = c.MustRegister(&someExampleCmd{})
+func (c *Commander) MustRegister(cmd Command) Command {
+	if !c.RegisterOnce(cmd) {
+		panic(fmt.Errorf("sub: %v: command %q is already registered", callerPackage(), cmd.Name()))
+	}
+	return cmd
+}
+
+// callerPackage returns the import path of the package that its
+// caller was called from. It returns "unknown" if that can't be
+// determined.
+func callerPackage() string {
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(3, pcs) == 0 {
+		return "unknown"
+	}
+
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	name := frame.Function
+	if name == "" {
+		return "unknown"
+	}
+
+	// Function names are in the form path/to/pkg.Func, where only the
+	// last element of the path can't contain a dot.
+	slash := strings.LastIndexByte(name, '/') + 1
+	if dot := strings.IndexByte(name[slash:], '.'); dot >= 0 {
+		return name[:slash+dot]
+	}
+	return name
+}
+
+// register is Register without the locking. The caller must hold c's
+// write lock.
+func (c *Commander) register(cmd Command) {
+	if nested := nestedOf(cmd); nested != nil {
+		nested.Commander.parent = c
+	}
+
+	c.add(cmd)
+	c.autoHelp()
+}
+
+// autoHelp registers a help command if c.AutoHelp is true and there
+// isn't one already. The caller must hold c's write lock.
+func (c *Commander) autoHelp() {
+	if c.AutoHelp && (c.lookup("help") == nil) {
+		c.add(c.HelpCmd())
+	}
+}
+
+// add registers cmd under its name and aliases without touching the
+// parent of a nested Commander. The caller must either hold c's write
+// lock or be the only one with access to c.
+func (c *Commander) add(cmd Command) {
+	c.remove(cmd.Name())
+
+	c.seq++
+	c.insert(entry{name: c.transformName(cmd.Name()), cmd: cmd, seq: c.seq})
+	if aliased, ok := cmd.(AliasedCommand); ok {
+		for _, alias := range aliased.Aliases() {
+			c.insert(entry{name: c.transformName(alias), cmd: cmd, seq: c.seq})
 		}
 	}
+}
+
+// Unregister removes the command with the given name, including all
+// of its aliases, from c. If name is an alias, the command that it
+// belongs to is removed. It returns false if no such command was
+// registered.
+func (c *Commander) Unregister(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cmd := c.lookup(name)
+	if cmd == nil {
+		return false
+	}
 
-	c.commands = append(c.commands, cmd)
+	if nested := nestedOf(cmd); nested != nil {
+		nested.Commander.parent = nil
+	}
+
+	return c.remove(cmd.Name())
+}
+
+// RegisterAll registers each of cmds, in order, as though by calling
+// Register. This makes it easy for a package to export a slice of
+// commands for clients to register all at once:
+//
+//    c.RegisterAll(pkg.Commands...)
+func (c *Commander) RegisterAll(cmds ...Command) {
+	for _, cmd := range cmds {
+		c.Register(cmd)
+	}
+}
+
+// RegisterGroup registers each of cmds, in order, as though by
+// calling Register, and puts all of them in the given group, replacing
+// any group that they already belonged to. This is equivalent to
+// wrapping each of them with WithGroup:
+//
+//    c.RegisterGroup("Resource", createCmd, deleteCmd)
+func (c *Commander) RegisterGroup(group string, cmds ...Command) {
+	for _, cmd := range cmds {
+		if grouped, ok := cmd.(groupCmd); ok {
+			cmd = grouped.Command
+		}
+		c.Register(WithGroup(cmd, group))
+	}
+}
+
+// Commands returns a snapshot of the commands registered with c,
+// sorted by name. Each command appears once, regardless of how many
+// aliases it has.
+func (c *Commander) Commands() []Command {
+	entries := c.entries()
+	cmds := make([]Command, 0, len(entries))
+	for _, e := range entries {
+		if c.canonical(e) {
+			cmds = append(cmds, e.cmd)
+		}
+	}
+	return cmds
+}
+
+// Count returns the number of commands registered with c. Aliases are
+// not counted separately, so it is always equal to the length of the
+// slice returned by Commands.
+func (c *Commander) Count() int {
+	var n int
+	for _, e := range c.entries() {
+		if c.canonical(e) {
+			n++
+		}
+	}
+	return n
+}
+
+// Empty returns true if no commands are registered with c.
+func (c *Commander) Empty() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.commands) == 0
+}
+
+// entries returns a copy of c's entries.
+func (c *Commander) entries() []entry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]entry(nil), c.commands...)
+}
+
+// insert inserts e into the sorted list of entries, replacing any
+// existing entry with the same name.
+func (c *Commander) insert(e entry) {
+	i := c.search(e.name)
+	if (i < len(c.commands)) && (c.commands[i].name == e.name) {
+		c.commands[i] = e
+		return
+	}
+
+	c.commands = append(c.commands, entry{})
+	copy(c.commands[i+1:], c.commands[i:])
+	c.commands[i] = e
+}
+
+// remove removes every entry belonging to the command whose canonical
+// name is name, including entries for its aliases. It returns whether
+// or not any entries were removed.
+func (c *Commander) remove(name string) bool {
+	commands := c.commands[:0]
+	for _, e := range c.commands {
+		if e.cmd.Name() != name {
+			commands = append(commands, e)
+		}
+	}
+	for i := len(commands); i < len(c.commands); i++ {
+		c.commands[i] = entry{}
+	}
+
+	removed := len(commands) < len(c.commands)
+	c.commands = commands
+	return removed
+}
+
+func (c *Commander) search(name string) int {
+	return sort.Search(len(c.commands), func(i int) bool {
+		return c.commands[i].name >= name
+	})
+}
+
+// hasGlobalFlags returns true if c has any global flags.
+func (c *Commander) hasGlobalFlags() bool {
+	return (c.Flags != nil) || (c.Version() != "")
+}
+
+// globalFlags populates fset with the global flags.
+func (c *Commander) globalFlags(fset *flag.FlagSet) {
+	if c.Flags != nil {
+		c.Flags(fset)
+	}
+	if c.Version() != "" {
+		fset.Bool(versionFlag, false, "print the version and exit")
+	}
+}
+
+// cmdFlags populates fset with the persistent flags followed by the
+// flags of cmd.
+func (c *Commander) cmdFlags(cmd Command, fset *flag.FlagSet) {
+	if c.PersistentFlags != nil {
+		c.PersistentFlags(fset)
+	}
+	cmd.Flags(fset)
+}
+
+// SetName sets the name of the Commander that is used in help output
+// before Run has been called. Run replaces it with its first argument
+// unless that argument is empty. It is safe to call concurrently with
+// Run, though help output that is being written at the same time may
+// use either name.
+func (c *Commander) SetName(name string) {
+	c.name.Store(name)
 }
 
-func (c *Commander) get(name string) Command {
-	for _, cmd := range c.commands {
-		if cmd.Name() == name {
+// Name returns the name of the Commander, as set by SetName or the
+// most recent call to Run. It returns an empty string if neither has
+// happened yet.
+func (c *Commander) Name() string {
+	name, _ := c.name.Load().(string)
+	return name
+}
+
+func (c *Commander) progName() string {
+	name := c.Name()
+	if name == "" {
+		return filepath.Base(os.Args[0])
+	}
+
+	return name
+}
+
+// Lookup returns the command registered with the given name or alias,
+// or nil if there is no such command.
+func (c *Commander) Lookup(name string) Command {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lookup(name)
+}
+
+// lookup is Lookup without locking.
+func (c *Commander) lookup(name string) Command {
+	i := c.search(name)
+	if (i < len(c.commands)) && (c.commands[i].name == name) {
+		return c.commands[i].cmd
+	}
+
+	if c.normalizesNames() {
+		if cmd := c.lookupNormalized(name); cmd != nil {
 			return cmd
 		}
 	}
 
+	if c.NameTransform != nil {
+		return c.lookupOriginal(name)
+	}
+
 	return nil
 }
 
+// Has returns true if a command is registered with the given name or
+// alias.
+func (c *Commander) Has(name string) bool {
+	return c.Lookup(name) != nil
+}
+
 // Run runs the commander against the given arguments. The first
 // argument should be the name of the executable. In many cases, this
 // should be filepath.Base(os.Args[0]).
 //
-// If there is a problem with args, such as an attempt to call a
-// non-existent command, flag.ErrHelp is returned. Otherwise, any
-// errors returned from subcommand's Run method are returned directly.
+// If there is a problem with args, the returned error describes it:
+//
+//    - flag.ErrHelp if help was explicitly requested with the -h or
+//      -help flags, or if no command was given and there is no Default
+//      or OnNoArgs to handle that case.
+//    - ErrNoCommand instead of flag.ErrHelp in the latter case if
+//      DisableBuiltinHelp is set.
+//    - *UnknownCommandError if the named command doesn't exist and
+//      there is no NotFound callback to handle it.
+//    - *FlagParseError if the global flags or the command's flags
+//      could not be parsed.
+//    - ErrVersion if the -version flag was given.
+//
+// Otherwise, any errors returned from the subcommand's Run method are
+// returned directly.
+//
+// As with the flag package, an argument of "--" ends flag parsing.
+// Before the command name, it ends the parsing of the global flags;
+// after it, it ends the parsing of the command's flags, and everything
+// following it is passed to the command as is. For example, running
+// with "prog", "cmd", "--", "--not-a-flag" passes "--not-a-flag" to
+// cmd.
+//
+// Run is equivalent to calling RunContext with context.Background().
 func (c *Commander) Run(args []string) error {
-	c.name = args[0]
+	return c.RunContext(context.Background(), args)
+}
 
-	fset := flag.NewFlagSet(args[0], flag.ContinueOnError)
-	fset.Usage = func() {
-		_ = c.HelpCmd().Run(nil)
+// RunContext is like Run, but passes ctx along to the command that is
+// run if that command implements CommandContext. Commands that don't
+// implement CommandContext are run using their Run method and never
+// see ctx. The context passed to commands also holds the parsed global
+// flags under GlobalFlagsKey.
+func (c *Commander) RunContext(ctx context.Context, args []string) error {
+	if flush := c.transformOutput(); flush != nil {
+		defer flush()
 	}
-	if c.Flags != nil {
-		c.Flags(fset)
+
+	cmd, global, fset, args, err := c.parse(args)
+	if (err == nil) && (cmd != nil) {
+		ctx = context.WithValue(ctx, GlobalFlagsKey, global)
+		if fset != nil {
+			ctx = context.WithValue(ctx, flagSetKey{}, fset)
+		}
+		err = c.DispatchContext(ctx, cmd, args)
+	}
+	c.handleError(err)
+	return err
+}
+
+// handleError calls the ErrorHandler, if there is one, with err.
+func (c *Commander) handleError(err error) {
+	if (err == nil) || (err == flag.ErrHelp) || (err == ErrVersion) {
+		return
+	}
+	if (c.ErrorHandler == nil) || (c.parent != nil) {
+		return
+	}
+
+	c.ErrorHandler(c.errOutput(), err)
+}
+
+// Parse parses args in the same way as Run, including global flag
+// parsing, command lookup, and parsing of the command's flags, but
+// does not run the resolved command. It returns the command along with
+// the arguments that should be passed to it, which can then be given
+// to Dispatch.
+//
+// If the resolved command is a nested Commander, as returned by
+// AsCommand, or a *PluginCommand, parsing stops there and the
+// remaining arguments are returned unparsed. Dispatching a nested
+// Commander parses them.
+//
+// If the NotFound or OnNoArgs callbacks are called and return nil,
+// Parse returns a nil Command and a nil error.
+func (c *Commander) Parse(args []string) (cmd Command, remaining []string, err error) {
+	cmd, _, _, remaining, err = c.parse(args)
+	return cmd, remaining, err
+}
+
+// parse is Parse, but also returns the parsed global FlagSet and the
+// command's parsed FlagSet. The latter is nil if the command is a
+// nested Commander.
+func (c *Commander) parse(args []string) (Command, *flag.FlagSet, *flag.FlagSet, []string, error) {
+	var cmd Command
+	if args[0] != "" {
+		c.SetName(args[0])
+	}
+
+	fset := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fset.Usage = c.usage
+	fset.SetOutput(c.errOutput())
+	c.quiet(fset)
+	c.globalFlags(fset)
+	err := fset.Parse(c.normalizeFlags(fset, args[1:], false))
+	c.global.Store(fset)
+	if err == flag.ErrHelp {
+		// The flag package has already called Usage unless it was
+		// disabled by quiet, so only print the help here if it wasn't.
+		if c.silent() && !c.helpDisabled() {
+			c.usage()
+		}
+		return nil, nil, nil, nil, c.flagError(err)
 	}
-	err := fset.Parse(args[1:])
 	if err != nil {
-		return err
+		return nil, nil, nil, nil, c.flagError(&FlagParseError{Err: err})
 	}
 
-	if fset.NArg() == 0 {
-		fset.Usage()
-		return flag.ErrHelp
+	if c.versionRequested(fset) {
+		c.printVersion(c.output())
+		return nil, nil, nil, nil, ErrVersion
 	}
 
-	cmd := c.get(fset.Arg(0))
-	if cmd == nil {
-		fmt.Fprintf(c.output(), "Error: No such command: %q\n\n", fset.Arg(0))
+	rest := fset.Args()
+	switch {
+	case (fset.NArg() == 0) && (c.Default != nil):
+		cmd = c.Default
+
+	case fset.NArg() == 0:
+		if c.OnNoArgs != nil {
+			return nil, nil, nil, nil, c.OnNoArgs(c.output())
+		}
+		if c.helpDisabled() {
+			return nil, nil, nil, nil, ErrNoCommand
+		}
 		fset.Usage()
-		return flag.ErrHelp
+		return nil, nil, nil, nil, flag.ErrHelp
+
+	default:
+		cmd = c.Lookup(fset.Arg(0))
+		if (cmd == nil) && c.abbreviates() {
+			var matches []string
+			cmd, matches = c.lookupPrefix(fset.Arg(0))
+			if len(matches) > 1 {
+				if !c.silent() && !c.helpDisabled() {
+					c.printAmbiguous(c.errOutput(), fset.Arg(0), matches)
+					fmt.Fprintln(c.errOutput())
+				}
+				fset.Usage()
+				return nil, nil, nil, nil, &AmbiguousCommandError{Name: fset.Arg(0), Matches: matches}
+			}
+		}
+		if cmd == nil {
+			if c.NotFound != nil {
+				return nil, nil, nil, nil, c.NotFound(c.errOutput(), fset.Arg(0))
+			}
+			if !c.silent() && !c.helpDisabled() {
+				c.printNotFound(c.errOutput(), fset.Arg(0))
+			}
+			fset.Usage()
+			return nil, nil, nil, nil, &UnknownCommandError{Name: fset.Arg(0)}
+		}
+		rest = rest[1:]
+	}
+
+	if nestedOf(cmd) != nil {
+		return cmd, fset, nil, rest, nil
+	}
+	if pluginOf(cmd) != nil {
+		return cmd, fset, nil, rest, nil
 	}
 
 	sub := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
 	sub.Usage = func() {
-		_ = c.HelpCmd().Run([]string{cmd.Name()})
+		c.commandUsage(cmd)
+	}
+	sub.SetOutput(c.errOutput())
+	c.quiet(sub)
+	c.cmdFlags(cmd, sub)
+	interspersed := c.interspersed(cmd)
+	rest, err = parseFlags(sub, c.normalizeFlags(sub, rest, interspersed), interspersed)
+	if err == flag.ErrHelp {
+		if c.silent() && !c.helpDisabled() {
+			c.commandUsage(cmd)
+		}
+		return nil, nil, nil, nil, c.flagError(err)
+	}
+	if err != nil {
+		return nil, nil, nil, nil, c.flagError(&FlagParseError{Cmd: cmd.Name(), Err: err})
 	}
-	cmd.Flags(sub)
-	err = sub.Parse(fset.Args()[1:])
+	err = c.applyEnv(cmd.Name(), sub)
 	if err != nil {
-		return err
+		// Report the error the same way that the flag package reports
+		// its own.
+		fmt.Fprintln(sub.Output(), err)
+		sub.Usage()
+		return nil, nil, nil, nil, c.flagError(&FlagParseError{Cmd: cmd.Name(), Err: err})
+	}
+	err = checkRequired(cmd, sub)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	err = c.checkArgs(cmd, rest)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return cmd, fset, sub, rest, nil
+}
+
+// usage prints the help summary, or calls UsageFunc if it is set.
+func (c *Commander) usage() {
+	if c.UsageFunc != nil {
+		c.UsageFunc()
+		return
+	}
+	_ = c.PrintHelp()
+}
+
+// commandUsage prints the help of cmd, or calls CommandUsageFunc or
+// UsageFunc if either is set.
+func (c *Commander) commandUsage(cmd Command) {
+	switch {
+	case c.CommandUsageFunc != nil:
+		c.CommandUsageFunc(cmd)
+	case c.UsageFunc != nil:
+		c.UsageFunc()
+	default:
+		_ = c.PrintCommandHelp(cmd.Name())
+	}
+}
+
+// silent returns true if c or any of its parents are silent.
+func (c *Commander) silent() bool {
+	return c.Silent || ((c.parent != nil) && c.parent.silent())
+}
+
+// helpDisabled returns true if c or any of its parents have
+// DisableBuiltinHelp set.
+func (c *Commander) helpDisabled() bool {
+	return c.DisableBuiltinHelp || ((c.parent != nil) && c.parent.helpDisabled())
+}
+
+// quiet disables fset's printing of usage and errors if c is silent or
+// its built-in help is disabled.
+func (c *Commander) quiet(fset *flag.FlagSet) {
+	if !c.silent() && !c.helpDisabled() {
+		return
+	}
+
+	fset.Usage = func() {}
+	fset.SetOutput(ioutil.Discard)
+}
+
+// Dispatch runs cmd with args, which should have been returned by a
+// previous call to Parse. It does no argument parsing of its own.
+//
+// Dispatch is equivalent to calling DispatchContext with
+// context.Background().
+func (c *Commander) Dispatch(cmd Command, args []string) error {
+	return c.DispatchContext(context.Background(), cmd, args)
+}
+
+// DispatchContext is like Dispatch, but passes ctx along to cmd in the
+// same way as RunContext.
+func (c *Commander) DispatchContext(ctx context.Context, cmd Command, args []string) error {
+	return c.dispatch(ctx, cmd, args, c.io())
+}
+
+// dispatch is DispatchContext, but gives cmd the streams in io instead
+// of c's own if it is an InputCommand or an IOCommand.
+func (c *Commander) dispatch(ctx context.Context, cmd Command, args []string, io IO) error {
+	if nested := nestedOf(cmd); nested != nil {
+		return nested.Commander.RunContext(ctx, append([]string{c.progName() + " " + nested.name}, args...))
+	}
+
+	if msg := deprecation(cmd); (msg != "") && !c.silent() {
+		fmt.Fprintf(c.errOutput(), "Warning: command %q is deprecated: %v\n", cmd.Name(), msg)
+	}
+
+	if cmd, ok := cmd.(InputCommand); ok {
+		cmd.SetInput(io.In)
+	}
+	if cmd, ok := cmd.(IOCommand); ok {
+		cmd.SetIO(io)
 	}
 
-	return cmd.Run(sub.Args())
+	if c.dryRun() {
+		return c.dryRunCommand(cmd, args)
+	}
+
+	run := RunFunc(func(cmd Command, args []string) error {
+		return c.run(ctx, cmd, args)
+	})
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		run = c.middleware[i](run)
+	}
+
+	return c.runHooks(cmd, args, run)
 }
 
 // Command is a subcommand.
@@ -157,15 +1068,193 @@ type Command interface {
 	Run(args []string) error
 }
 
+// CommandContext is a Command that can make use of a context.Context.
+// If a command implements CommandContext, Commander.RunContext calls
+// its RunContext method instead of its Run method.
+//
+// Long-running commands should check ctx.Done() periodically and
+// return, usually with ctx.Err(), if it has been closed.
+type CommandContext interface {
+	Command
+
+	// RunContext is like Run, but is also passed the context that was
+	// given to Commander.RunContext.
+	RunContext(ctx context.Context, args []string) error
+}
+
+// AliasedCommand is a Command that can also be run using alternate
+// names.
+type AliasedCommand interface {
+	Command
+
+	// Aliases returns the alternate names of the command. The help
+	// listing shows them alongside the command's canonical name.
+	Aliases() []string
+}
+
+// RequiredFlagsCommand is a Command that has flags which must be
+// explicitly set on the command line.
+type RequiredFlagsCommand interface {
+	Command
+
+	// RequiredFlags returns the names of the required flags. A required
+	// flag that is not set results in an error before the command is
+	// run, even if the flag's default value is not the zero value.
+	RequiredFlags() []string
+}
+
+// checkRequired returns an error if fset, having already been parsed,
+// is missing any of the flags required by cmd.
+func checkRequired(cmd Command, fset *flag.FlagSet) error {
+	required, ok := cmd.(RequiredFlagsCommand)
+	if !ok {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	fset.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for _, name := range required.RequiredFlags() {
+		if !set[name] {
+			return fmt.Errorf("flag -%v is required", name)
+		}
+	}
+
+	return nil
+}
+
+// ExampleProvider is a Command that provides usage examples. The
+// examples are shown at the end of the command's help.
+type ExampleProvider interface {
+	Command
+
+	// Example returns the command's examples. Multiple examples should
+	// be separated by blank lines. Indentation is added automatically
+	// when the examples are displayed.
+	Example() string
+}
+
+// SeeAlsoProvider is a Command that refers to other related commands
+// or resources. They are listed at the end of the command's help.
+type SeeAlsoProvider interface {
+	Command
+
+	// SeeAlso returns the names of the related commands or resources.
+	// They do not need to be the names of registered commands.
+	SeeAlso() []string
+}
+
+// seeAlsoOf returns the names that cmd refers to, if any.
+func seeAlsoOf(cmd Command) []string {
+	if provider, ok := cmd.(SeeAlsoProvider); ok {
+		return provider.SeeAlso()
+	}
+	return nil
+}
+
+// examplesOf returns the examples provided by cmd, each one trimmed of
+// surrounding whitespace.
+func examplesOf(cmd Command) []string {
+	provider, ok := cmd.(ExampleProvider)
+	if !ok {
+		return nil
+	}
+
+	var examples []string
+	for _, example := range strings.Split(strings.TrimSpace(provider.Example()), "\n\n") {
+		if example = strings.TrimSpace(example); example != "" {
+			examples = append(examples, example)
+		}
+	}
+	return examples
+}
+
+// displayName returns the name of cmd as displayed in the help
+// listing, which includes any aliases that it has.
+func (c *Commander) displayName(cmd Command) string {
+	names := []string{c.transformName(cmd.Name())}
+	if aliased, ok := cmd.(AliasedCommand); ok {
+		for _, alias := range aliased.Aliases() {
+			names = append(names, c.transformName(alias))
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+type commanderCmd struct {
+	*Commander
+	name string
+	desc string
+}
+
+// nestedOf returns cmd as a nested Commander, looking through the
+// package's wrappers, or nil if it isn't one.
+func nestedOf(cmd Command) *commanderCmd {
+	if w, ok := cmd.(interface{ nested() *commanderCmd }); ok {
+		return w.nested()
+	}
+	nested, _ := cmd.(*commanderCmd)
+	return nested
+}
+
+// AsCommand returns a Command that runs c as a nested subcommand of
+// another Commander. The returned Command is listed in the parent's
+// help as name with the description desc, and c's Help field is used
+// as its longer help message.
+//
+// When the parent's Run encounters the nested command, the remaining
+// arguments are handed directly to c's Run, so c's own global flags
+// are parsed relative to its position on the command line rather
+// than the root's. If c's Output is nil, it inherits the output of
+// the Commander that it is registered with.
+func (c *Commander) AsCommand(name, desc string) Command {
+	return &commanderCmd{
+		Commander: c,
+		name:      name,
+		desc:      desc,
+	}
+}
+
+func (cmd *commanderCmd) Name() string {
+	return cmd.name
+}
+
+func (cmd *commanderCmd) Desc() string {
+	return cmd.desc
+}
+
+func (cmd *commanderCmd) Help() string {
+	return cmd.Commander.Help
+}
+
+func (cmd *commanderCmd) Flags(fset *flag.FlagSet) {
+	cmd.Commander.globalFlags(fset)
+}
+
+func (cmd *commanderCmd) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+func (cmd *commanderCmd) RunContext(ctx context.Context, args []string) error {
+	return cmd.Commander.RunContext(ctx, append([]string{cmd.name}, args...))
+}
+
 type helpCmd struct {
 	*Commander
+	out    io.Writer
+	all    bool
+	long   bool
+	format string
 }
 
 // HelpCmd returns a "help" Command that provides help for c. If
 // clients want an explicit "help" command to be available, this must
 // be manually registered.
 func (c *Commander) HelpCmd() Command {
-	return &helpCmd{c}
+	return &helpCmd{Commander: c}
 }
 
 func (h *helpCmd) Name() string {
@@ -177,65 +1266,362 @@ func (h *helpCmd) Desc() string {
 }
 
 func (h *helpCmd) Help() string {
-	return `Usage: help [command]
+	return `Usage: help [options] [command]
 
 help displays a help summary for the entire set of commands or it
-shows more detailed help for a specific named subcommand.`
+shows more detailed help for a specific named subcommand. With the
+-long flag, the summary also includes the program's extended
+description, if it has one.`
 }
 
-func (h *helpCmd) Flags(*flag.FlagSet) {
+// ValidateArgs exempts help from MinArgs and MaxArgs.
+func (h *helpCmd) ValidateArgs(args []string) error {
+	return nil
 }
 
-func (h *helpCmd) Run(args []string) error {
-	if len(args) == 0 {
-		name := h.name
-		if name == "" {
-			name = filepath.Base(os.Args[0])
+func (h *helpCmd) Flags(fset *flag.FlagSet) {
+	fset.BoolVar(&h.all, "all", false, "include hidden commands in the summary")
+	fset.BoolVar(&h.long, "long", false, "include the extended description in the summary")
+	fset.StringVar(&h.format, "format", "text", "output format, either text or markdown")
+}
+
+// clone returns a help command for c that uses the same output and
+// flag values as h.
+func (h *helpCmd) clone(c *Commander) *helpCmd {
+	return &helpCmd{
+		Commander: c,
+		out:       h.out,
+		all:       h.all,
+		long:      h.long,
+		format:    h.format,
+	}
+}
+
+// output shadows the method promoted from the embedded Commander so
+// that the help output can be redirected without modifying the
+// Commander.
+func (h *helpCmd) output() io.Writer {
+	if h.out != nil {
+		return h.out
+	}
+	return h.Commander.output()
+}
+
+// HelpString returns the help summary of c, as would be shown by
+// running its help command without arguments. c's Output is not
+// written to.
+func (c *Commander) HelpString() string {
+	var buf bytes.Buffer
+	_ = (&helpCmd{Commander: c, out: &buf}).Run(nil)
+	return buf.String()
+}
+
+// CommandHelpString returns the help of the named command, as would be
+// shown by running c's help command with name as an argument. c's
+// Output is not written to. As with Run when help is requested, the
+// returned error is flag.ErrHelp if the help was written successfully.
+// If there is no such command, an *UnknownCommandError is returned
+// instead.
+func (c *Commander) CommandHelpString(name string) (string, error) {
+	if !c.Has(name) {
+		return "", &UnknownCommandError{Name: name}
+	}
+
+	var buf bytes.Buffer
+	err := (&helpCmd{Commander: c, out: &buf}).Run([]string{name})
+	if err == nil {
+		err = flag.ErrHelp
+	}
+	return buf.String(), err
+}
+
+// PrintHelp writes the help summary of c to c's Output, exactly as
+// running its help command without arguments would.
+func (c *Commander) PrintHelp() error {
+	return c.HelpCmd().Run(nil)
+}
+
+// PrintCommandHelp writes the help of the named command to c's Output,
+// exactly as running c's help command with name as an argument would.
+// If there is no such command, the summary is printed instead and
+// flag.ErrHelp is returned.
+func (c *Commander) PrintCommandHelp(name string) error {
+	return c.HelpCmd().Run([]string{name})
+}
+
+// longHelp returns the Commander's LongHelp with surrounding
+// whitespace removed if the -long flag was given, or an empty string
+// otherwise.
+func (h *helpCmd) longHelp() string {
+	if !h.long {
+		return ""
+	}
+	return strings.TrimSpace(h.LongHelp)
+}
+
+// listed returns the commands that should be shown in the command
+// listing, ordered by priority and then by the Commander's Sort field.
+func (h *helpCmd) listed() []Command {
+	var entries []entry
+	for _, e := range h.entries() {
+		if !h.canonical(e) || (isHidden(e.cmd) && !h.all) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if h.Sort != nil {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		pi, pj := priorityOf(entries[i].cmd), priorityOf(entries[j].cmd)
+		if pi != pj {
+			return pi < pj
+		}
+		return (h.Sort != nil) && h.Sort(entries[i].cmd, entries[j].cmd)
+	})
+
+	cmds := make([]Command, 0, len(entries))
+	for _, e := range entries {
+		cmds = append(cmds, e.cmd)
+	}
+	return cmds
+}
+
+// describe returns the description of cmd for the command listing,
+// including any annotations.
+func (h *helpCmd) describe(cmd Command) string {
+	desc := cmd.Desc()
+	if isHidden(cmd) {
+		desc += " [hidden]"
+	}
+	if deprecation(cmd) != "" {
+		desc += " [deprecated]"
+	}
+	if (h.Default != nil) && (h.Default.Name() == cmd.Name()) {
+		desc += " (default)"
+	}
+	return desc
+}
+
+// usage returns the usage line for the Commander.
+func (h *helpCmd) usage() string {
+	globalOptions := ""
+	if h.hasGlobalFlags() {
+		globalOptions = " [global options]"
+	}
+
+	return fmt.Sprintf("Usage: %v%v <subcommand> [subcommand arguments]", h.progName(), globalOptions)
+}
+
+// globalDefaults returns the defaults of the global flags, as printed
+// by flag.FlagSet.PrintDefaults.
+func (h *helpCmd) globalDefaults() string {
+	var buf bytes.Buffer
+	fset := flag.NewFlagSet(h.progName(), flag.ContinueOnError)
+	fset.SetOutput(&buf)
+	h.globalFlags(fset)
+	fset.PrintDefaults()
+	return buf.String()
+}
+
+// cmdDefaults returns the defaults of the flags of cmd, including any
+// persistent flags, as printed by flag.FlagSet.PrintDefaults. Flags
+// whose names are in hide are left out.
+func (h *helpCmd) cmdDefaults(cmd Command, hide ...string) string {
+	fset := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	h.cmdFlags(cmd, fset)
+
+	var buf bytes.Buffer
+	shown := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	shown.SetOutput(&buf)
+	fset.VisitAll(func(f *flag.Flag) {
+		for _, name := range hide {
+			if f.Name == name {
+				return
+			}
 		}
+		shown.Var(f.Value, f.Name, f.Usage)
+	})
+	shown.PrintDefaults()
+	return buf.String()
+}
+
+// RunContext shadows the method promoted from the embedded
+// Commander, which would otherwise be called instead of Run.
+func (h *helpCmd) RunContext(ctx context.Context, args []string) error {
+	return h.Run(args)
+}
 
-		globalOptions := ""
-		if h.Commander.Flags != nil {
-			globalOptions = " [global options]"
+// printCommands prints the command listing of cmds to w, split into
+// one section per group. The descriptions of every command are aligned
+// to the same column, regardless of which group a command is in.
+func (h *helpCmd) printCommands(w io.Writer, cmds []Command) {
+	var groups []string
+	members := make(map[string][]Command)
+	var width int
+	for _, cmd := range cmds {
+		group := groupOf(cmd)
+		if _, ok := members[group]; !ok {
+			groups = append(groups, group)
 		}
+		members[group] = append(members[group], cmd)
 
-		fmt.Fprintf(h.output(), "Usage: %v%v <subcommand> [subcommand arguments]\n", name, globalOptions)
-		if h.Commander.Help != "" {
-			fmt.Fprintf(h.output(), "\n%v\n", strings.TrimSpace(h.Commander.Help))
+		if w := utf8.RuneCountInString(h.displayName(cmd)); w > width {
+			width = w
 		}
-		if h.Commander.Flags != nil {
-			fmt.Fprintf(h.output(), "\nGlobal Options:\n")
-			fset := flag.NewFlagSet(name, flag.ContinueOnError)
-			h.Commander.Flags(fset)
-			fset.PrintDefaults()
+	}
+	sort.Strings(groups)
+
+	padding := h.HelpPadding
+	if padding <= 0 {
+		padding = 2
+	}
+
+	// The leading tab of each line is escaped so that the tabwriter
+	// treats it as part of the name column. It counts as a single
+	// character of that column, hence the extra 1 in minwidth. The
+	// tabwriter measures cells in runes, as is width, so multi-byte
+	// names are aligned correctly. The group headers end the
+	// tabwriter's column blocks, so a minwidth of 1 would let each
+	// group's names determine their own width. Using the widest name
+	// as the minimum width instead keeps the columns of every group
+	// aligned. The tabwidth is unused, as cells are padded with spaces.
+	tw := tabwriter.NewWriter(w, width+1+padding, 4, padding, ' ', tabwriter.StripEscape)
+	defer tw.Flush()
+
+	descWidth := h.width() - (tabWidth + width + padding)
+
+	if len(members[""]) > 0 || len(groups) == 0 {
+		fmt.Fprintf(tw, "\n%v\n", h.style(ansi.Underline, "Commands:"))
+		h.printGroup(tw, members[""], descWidth)
+	}
+	for _, group := range groups {
+		if group == "" {
+			continue
+		}
+
+		fmt.Fprintf(tw, "\n%v\n", h.style(ansi.Underline, group+" commands:"))
+		h.printGroup(tw, members[group], descWidth)
+	}
+}
+
+// printGroup prints a single section of the command listing, wrapping
+// descriptions to descWidth.
+func (h *helpCmd) printGroup(w io.Writer, cmds []Command, descWidth int) {
+	for _, cmd := range cmds {
+		descStyle := ""
+		if deprecation(cmd) != "" {
+			descStyle = ansi.Dim
 		}
-		fmt.Fprintf(h.output(), "\nCommands:\n")
-		for _, cmd := range h.commands {
-			fmt.Fprintf(h.output(), "\t%v\t\t%v\n", cmd.Name(), cmd.Desc())
+
+		// Every name cell, including the empty ones on continuation
+		// lines, is styled the same way so that the escape sequences add
+		// the same width to each one and the columns stay aligned.
+		lines := wrap(h.describe(cmd), descWidth)
+		fmt.Fprintf(w, "\xff\t\xff%v\t%v\n", h.style(ansi.Bold, h.displayName(cmd)), h.styleDesc(descStyle, lines[0]))
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "\xff\t\xff%v\t%v\n", h.style(ansi.Bold, ""), h.styleDesc(descStyle, line))
 		}
+	}
+}
 
+func (h *helpCmd) Run(args []string) error {
+	switch h.format {
+	case "", "text":
+		return h.text(args)
+	case "markdown":
+		return h.markdown(args)
+	default:
+		return fmt.Errorf("unknown help format: %q", h.format)
+	}
+}
+
+// text writes help in the plain text format.
+func (h *helpCmd) text(args []string) error {
+	if len(args) == 0 {
+		h.renderer().RenderGlobalHelp(h.output(), h.Commander)
 		return nil
 	}
 
-	cmd := h.get(args[0])
+	cmd := h.Lookup(args[0])
 	if cmd == nil {
-		fmt.Fprintf(h.output(), "Error: No such command: %q\n\n", args[0])
-		_ = h.Run(nil)
+		h.printNotFound(h.output(), args[0])
+		_ = h.text(nil)
 		return flag.ErrHelp
 	}
 
+	if nested := nestedOf(cmd); nested != nil {
+		nested.Commander.SetName(h.progName() + " " + nested.name)
+		return h.clone(nested.Commander).Run(args[1:])
+	}
+
+	var hide []string
+	if _, ok := cmd.(*helpCmd); ok {
+		hide = []string{"format"}
+	}
+	h.renderer().RenderCommandHelp(h.output(), cmd, h.cmdDefaults(cmd, hide...))
+
+	return nil
+}
+
+// textSummary writes the help summary of the Commander.
+func (h *helpCmd) textSummary() {
+	data := h.helpData(false)
+
+	fmt.Fprintf(h.output(), "%v\n", data.Usage)
+	if data.Help != "" {
+		fmt.Fprintf(h.output(), "\n%v\n", data.Help)
+	}
+	if data.LongHelp != "" {
+		fmt.Fprintf(h.output(), "\n%v\n", data.LongHelp)
+	}
+	if h.hasGlobalFlags() {
+		fmt.Fprintf(h.output(), "\n%v\n%v", h.style(ansi.Underline, "Global Options:"), h.globalDefaults())
+	}
+	if header := strings.TrimSpace(h.Header); header != "" {
+		fmt.Fprintf(h.output(), "\n%v\n", header)
+	}
+	cmds := make([]Command, 0, len(data.Commands))
+	for _, cmd := range data.Commands {
+		cmds = append(cmds, cmd.cmd)
+	}
+	h.printCommands(h.output(), cmds)
+	if footer := strings.TrimSpace(h.Footer); footer != "" {
+		fmt.Fprintf(h.output(), "\n%v\n", footer)
+	}
+}
+
+// textCommand writes the help of cmd, using defaults as the
+// description of its flags.
+func (h *helpCmd) textCommand(cmd Command, defaults string) {
+	if msg := deprecation(cmd); msg != "" {
+		fmt.Fprintf(h.output(), "Deprecated: %v\n\n", msg)
+	}
+
 	if cmd.Help() != "" {
 		fmt.Fprintf(h.output(), "%v\n", strings.TrimSpace(cmd.Help()))
 	}
 
-	var optionBuf bytes.Buffer
-	fset := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
-	fset.SetOutput(&optionBuf)
-	cmd.Flags(fset)
-	fset.PrintDefaults()
-	if optionBuf.Len() > 0 {
-		fmt.Fprintf(h.output(), "\nOptions:\n")
-		_, _ = io.Copy(h.output(), &optionBuf)
+	if defaults != "" {
+		fmt.Fprintf(h.output(), "\n%v\n%v", h.style(ansi.Underline, "Options:"), defaults)
 	}
 
-	return nil
+	if examples := examplesOf(cmd); len(examples) > 0 {
+		fmt.Fprintf(h.output(), "\n%v\n", h.style(ansi.Underline, "Examples:"))
+		for i, example := range examples {
+			if i > 0 {
+				fmt.Fprintln(h.output())
+			}
+			fmt.Fprintf(h.output(), "  %v\n", strings.Replace(example, "\n", "\n  ", -1))
+		}
+	}
+
+	if names := seeAlsoOf(cmd); len(names) > 0 {
+		links := make([]string, 0, len(names))
+		for _, name := range names {
+			links = append(links, h.link(name))
+		}
+		fmt.Fprintf(h.output(), "\nSee also: %v\n", strings.Join(links, ", "))
+	}
 }
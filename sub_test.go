@@ -2,9 +2,15 @@ package sub_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/DeedleFake/sub"
@@ -56,8 +62,8 @@ func TestSimpleCmd(t *testing.T) {
 Even more help text.
 
 Commands:
-	help		show help for commands
-	test		a simple test
+	help  show help for commands
+	test  a simple test
 `,
 			testout: ``,
 			ret:     flag.ErrHelp,
@@ -79,6 +85,7 @@ Options:
 	}
 
 	for _, test := range tests {
+		test := test
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
@@ -113,3 +120,1200 @@ Even more help text.
 		})
 	}
 }
+
+func TestNestedCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		cout    string
+		testout string
+		ret     error
+	}{
+		{
+			name: "Root Help",
+			args: []string{"subtest", "help"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+	mid   the middle level
+`,
+		},
+		{
+			name: "Middle Help",
+			args: []string{"subtest", "help", "mid"},
+			cout: `Usage: subtest mid <subcommand> [subcommand arguments]
+
+The middle level.
+
+Commands:
+	help  show help for commands
+	leaf  the bottom level
+`,
+		},
+		{
+			name: "Leaf Help",
+			args: []string{"subtest", "mid", "leaf", "--help"},
+			cout: `Usage: subtest mid leaf [global options] <subcommand> [subcommand arguments]
+
+The bottom level.
+
+Global Options:
+  -global string
+    	a global flag (default "global")
+
+Commands:
+	test  a simple test
+`,
+			ret: flag.ErrHelp,
+		},
+		{
+			name:    "Leaf Run",
+			args:    []string{"subtest", "mid", "leaf", "-global", "set", "test", "arg"},
+			testout: `"arg"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var testout bytes.Buffer
+
+			leaf := &sub.Commander{
+				Help:  "The bottom level.",
+				Flags: func(fset *flag.FlagSet) { fset.String("global", "global", "a global flag") },
+			}
+			leaf.Register(&testCmd{w: &testout})
+
+			mid := &sub.Commander{
+				Help: "The middle level.",
+			}
+			mid.Register(mid.HelpCmd())
+			mid.Register(leaf.AsCommand("leaf", "the bottom level"))
+
+			c := &sub.Commander{
+				Output: &cout,
+			}
+			c.Register(c.HelpCmd())
+			c.Register(mid.AsCommand("mid", "the middle level"))
+
+			err := c.Run(test.args)
+			if err != test.ret {
+				t.Errorf("Expected:\t%v", test.ret)
+				t.Errorf("Got:\t\t%v", err)
+			}
+
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+
+			if out := testout.String(); out != test.testout {
+				t.Errorf("Expected:\t%q", test.testout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
+
+type ctxCmd struct {
+	started chan struct{}
+}
+
+func (cmd *ctxCmd) Name() string {
+	return "wait"
+}
+
+func (cmd *ctxCmd) Desc() string {
+	return "wait for cancellation"
+}
+
+func (cmd *ctxCmd) Help() string {
+	return ""
+}
+
+func (cmd *ctxCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *ctxCmd) Run(args []string) error {
+	panic("Run called instead of RunContext")
+}
+
+func (cmd *ctxCmd) RunContext(ctx context.Context, args []string) error {
+	close(cmd.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunContext(t *testing.T) {
+	var c sub.Commander
+	cmd := &ctxCmd{started: make(chan struct{})}
+	c.Register(cmd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-cmd.started
+		cancel()
+	}()
+
+	err := c.RunContext(ctx, []string{"subtest", "wait"})
+	if err != context.Canceled {
+		t.Errorf("Expected:\t%v", context.Canceled)
+		t.Errorf("Got:\t\t%v", err)
+	}
+}
+
+type aliasedCmd struct {
+	ran *bool
+}
+
+func (cmd *aliasedCmd) Name() string {
+	return "rm"
+}
+
+func (cmd *aliasedCmd) Aliases() []string {
+	return []string{"remove", "del"}
+}
+
+func (cmd *aliasedCmd) Desc() string {
+	return "delete a resource"
+}
+
+func (cmd *aliasedCmd) Help() string {
+	return "Usage: rm <resource>"
+}
+
+func (cmd *aliasedCmd) Flags(fset *flag.FlagSet) {
+}
+
+func (cmd *aliasedCmd) Run(args []string) error {
+	*cmd.ran = true
+	return nil
+}
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		cout string
+		ran  bool
+	}{
+		{
+			name: "Listing",
+			args: []string{"subtest", "help"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help             show help for commands
+	rm, remove, del  delete a resource
+	test             a simple test
+`,
+		},
+		{
+			name: "Alias Help",
+			args: []string{"subtest", "help", "remove"},
+			cout: "Usage: rm <resource>\n",
+		},
+		{
+			name: "Canonical Help",
+			args: []string{"subtest", "help", "rm"},
+			cout: "Usage: rm <resource>\n",
+		},
+		{
+			name: "Run Alias",
+			args: []string{"subtest", "del"},
+			ran:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var ran bool
+
+			c := &sub.Commander{Output: &cout}
+			c.Register(&testCmd{})
+			c.Register(&aliasedCmd{ran: &ran})
+			c.Register(c.HelpCmd())
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+
+			if ran != test.ran {
+				t.Errorf("Expected ran:\t%v", test.ran)
+				t.Errorf("Got:\t\t%v", ran)
+			}
+		})
+	}
+}
+
+func TestPersistentFlags(t *testing.T) {
+	var cout bytes.Buffer
+	var testout bytes.Buffer
+	var verbose bool
+
+	c := &sub.Commander{
+		Output: &cout,
+		PersistentFlags: func(fset *flag.FlagSet) {
+			fset.BoolVar(&verbose, "v", false, "verbose output")
+		},
+	}
+	c.Register(c.HelpCmd())
+	c.Register(&testCmd{w: &testout})
+
+	err := c.Run([]string{"subtest", "test", "-v", "-flag", "set", "arg"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !verbose {
+		t.Errorf("Persistent flag was not set")
+	}
+
+	err = c.Run([]string{"subtest", "help", "test"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `This is just a simple test.
+No, really. That's it.
+Probably.
+
+Options:
+  -flag string
+    	a flag test (default "test")
+  -v	verbose output
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+type requiredCmd struct {
+	ran bool
+}
+
+func (cmd *requiredCmd) Name() string {
+	return "login"
+}
+
+func (cmd *requiredCmd) Desc() string {
+	return "log in"
+}
+
+func (cmd *requiredCmd) Help() string {
+	return ""
+}
+
+func (cmd *requiredCmd) Flags(fset *flag.FlagSet) {
+	fset.String("token", "", "authentication token")
+	fset.String("server", "localhost", "server to log in to")
+	fset.Bool("v", false, "verbose output")
+}
+
+func (cmd *requiredCmd) RequiredFlags() []string {
+	return []string{"token", "server"}
+}
+
+func (cmd *requiredCmd) Run(args []string) error {
+	cmd.ran = true
+	return nil
+}
+
+func TestRequiredFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{
+			name: "All Present",
+			args: []string{"subtest", "login", "-token", "abc", "-server", "example.com"},
+		},
+		{
+			name: "Missing",
+			args: []string{"subtest", "login", "-server", "example.com", "-v"},
+			err:  "flag -token is required",
+		},
+		{
+			name: "Missing With Default",
+			args: []string{"subtest", "login", "-token", "abc"},
+			err:  "flag -server is required",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var c sub.Commander
+			cmd := &requiredCmd{}
+			c.Register(cmd)
+
+			err := c.Run(test.args)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !cmd.ran {
+					t.Fatalf("Command did not run")
+				}
+				return
+			}
+
+			if (err == nil) || (err.Error() != test.err) {
+				t.Errorf("Expected:\t%v", test.err)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if cmd.ran {
+				t.Errorf("Command ran despite missing flag")
+			}
+		})
+	}
+}
+
+func TestParseDispatch(t *testing.T) {
+	var testout bytes.Buffer
+
+	var c sub.Commander
+	c.Register(&testCmd{w: &testout})
+
+	cmd, args, err := c.Parse([]string{"subtest", "test", "-flag", "set", "one", "two"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name := cmd.Name(); name != "test" {
+		t.Errorf("Expected:\t%q", "test")
+		t.Errorf("Got:\t\t%q", name)
+	}
+	if want := []string{"one", "two"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", args)
+	}
+	if testout.Len() != 0 {
+		t.Fatalf("Command was run by Parse")
+	}
+
+	err = c.Dispatch(cmd, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out := testout.String(); out != `"one"` {
+		t.Errorf("Expected:\t%q", `"one"`)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestRegisterAll(t *testing.T) {
+	var c sub.Commander
+	c.RegisterAll(
+		&testCmd{},
+		&aliasedCmd{},
+		c.HelpCmd(),
+	)
+
+	var names []string
+	for _, cmd := range c.Commands() {
+		names = append(names, cmd.Name())
+	}
+	if want := []string{"help", "rm", "test"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", names)
+	}
+}
+
+func TestRegisterOnce(t *testing.T) {
+	var c sub.Commander
+	original := &testCmd{}
+	if !c.RegisterOnce(original) {
+		t.Errorf("First registration failed")
+	}
+	if c.RegisterOnce(&testCmd{}) {
+		t.Errorf("Duplicate registration succeeded")
+	}
+	if cmd := c.Lookup("test"); cmd != original {
+		t.Errorf("Original command was replaced: %p != %p", cmd, original)
+	}
+
+	c.Register(&aliasedCmd{})
+	if c.RegisterOnce(sub.Func("remove", "", "", nil, nil)) {
+		t.Errorf("Registration under an existing alias succeeded")
+	}
+}
+
+func TestMustRegister(t *testing.T) {
+	var c sub.Commander
+	cmd := &testCmd{}
+	if got := c.MustRegister(cmd); got != cmd {
+		t.Errorf("MustRegister returned %v", got)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Duplicate registration did not panic")
+		}
+		msg := fmt.Sprint(r)
+		for _, want := range []string{`"test"`, "github.com/DeedleFake/sub_test"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("Panic message %q does not contain %q", msg, want)
+			}
+		}
+		if c.Lookup("test") != cmd {
+			t.Errorf("Original command was replaced")
+		}
+	}()
+	c.MustRegister(&testCmd{})
+}
+
+func TestCount(t *testing.T) {
+	var c sub.Commander
+	if !c.Empty() || (c.Count() != 0) {
+		t.Errorf("New Commander not empty: %v", c.Count())
+	}
+
+	c.RegisterAll(&testCmd{}, &aliasedCmd{})
+	if c.Empty() {
+		t.Errorf("Commander with commands is empty")
+	}
+	if n := c.Count(); n != 2 {
+		t.Errorf("Expected:\t%v", 2)
+		t.Errorf("Got:\t\t%v", n)
+	}
+
+	c.Unregister("rm")
+	c.Unregister("test")
+	if !c.Empty() || (c.Count() != 0) {
+		t.Errorf("Commander not empty after unregistering: %v", c.Count())
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout}
+	c.RegisterAll(c.HelpCmd(), &testCmd{}, &aliasedCmd{})
+
+	if c.Unregister("missing") {
+		t.Errorf("Unregistered a command that doesn't exist")
+	}
+	if !c.Unregister("remove") {
+		t.Errorf("Failed to unregister a command by alias")
+	}
+	if !c.Unregister("test") {
+		t.Errorf("Failed to unregister a command")
+	}
+
+	for _, name := range []string{"test", "rm", "remove", "del"} {
+		cout.Reset()
+		err := c.Run([]string{"subtest", name})
+		var unknown *sub.UnknownCommandError
+		if !errors.As(err, &unknown) || (unknown.Name != name) {
+			t.Errorf("Expected:\t%v", &sub.UnknownCommandError{Name: name})
+			t.Errorf("Got:\t\t%v", err)
+		}
+	}
+
+	cout.Reset()
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var c sub.Commander
+	cmd := &aliasedCmd{}
+	c.Register(cmd)
+
+	for _, name := range []string{"rm", "remove", "del"} {
+		if got := c.Lookup(name); got != cmd {
+			t.Errorf("Lookup(%q) returned %v", name, got)
+		}
+		if !c.Has(name) {
+			t.Errorf("Has(%q) returned false", name)
+		}
+	}
+
+	if got := c.Lookup("help"); got != nil {
+		t.Errorf("Lookup(%q) returned %v", "help", got)
+	}
+	if c.Has("help") {
+		t.Errorf("Has(%q) returned true", "help")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	var cout bytes.Buffer
+	var ran bool
+
+	c := &sub.Commander{Output: &cout}
+	def := sub.Func("run", "run the program", "", nil, func(args []string) error {
+		ran = true
+		return nil
+	})
+	c.RegisterAll(c.HelpCmd(), def)
+	c.Default = def
+
+	err := c.Run([]string{"subtest"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ran {
+		t.Errorf("Default command did not run")
+	}
+
+	err = c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+	run   run the program (default)
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	var cout bytes.Buffer
+	notFound := errors.New("not found")
+	noArgs := errors.New("no args")
+
+	c := &sub.Commander{
+		Output: &cout,
+		NotFound: func(w io.Writer, name string) error {
+			fmt.Fprintf(w, "unknown: %v\n", name)
+			return notFound
+		},
+		OnNoArgs: func(w io.Writer) error {
+			fmt.Fprintf(w, "nothing to do\n")
+			return noArgs
+		},
+	}
+	c.Register(&testCmd{})
+
+	err := c.Run([]string{"subtest", "missing"})
+	if err != notFound {
+		t.Errorf("Expected:\t%v", notFound)
+		t.Errorf("Got:\t\t%v", err)
+	}
+
+	err = c.Run([]string{"subtest"})
+	if err != noArgs {
+		t.Errorf("Expected:\t%v", noArgs)
+		t.Errorf("Got:\t\t%v", err)
+	}
+
+	if want := "unknown: missing\nnothing to do\n"; cout.String() != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cout.String())
+	}
+
+	c.NotFound = func(io.Writer, string) error { return nil }
+	err = c.Run([]string{"subtest", "missing"})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHelpPadding(t *testing.T) {
+	tests := []struct {
+		name    string
+		padding int
+		cout    string
+	}{
+		{
+			name: "Default",
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help                  show help for commands
+	reticulate-splines-x  reticulate the splines
+`,
+		},
+		{
+			name:    "Custom",
+			padding: 4,
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help                    show help for commands
+	reticulate-splines-x    reticulate the splines
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+
+			c := &sub.Commander{
+				Output:      &cout,
+				HelpPadding: test.padding,
+			}
+			c.RegisterAll(
+				c.HelpCmd(),
+				sub.Func("reticulate-splines-x", "reticulate the splines", "", nil, nil),
+			)
+
+			err := c.Run([]string{"subtest", "help"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
+
+func TestHelpUnicode(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout}
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.Func("🚀launch", "launch the rocket", "", nil, nil),
+		sub.WithGroup(sub.Func("déployer", "deploy things", "", nil, nil), "Deployment"),
+	)
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help      show help for commands
+	🚀launch   launch the rocket
+
+Deployment commands:
+	déployer  deploy things
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	var cout bytes.Buffer
+
+	desc := "reticulate the splines, then frobnicate the widgets, then do it all again, until everything is done."
+	if len(desc) != 100 {
+		t.Fatalf("Description is %v characters long", len(desc))
+	}
+
+	c := &sub.Commander{
+		Output:   &cout,
+		MaxWidth: 60,
+	}
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.Func("splines", desc, "", nil, nil),
+	)
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help     show help for commands
+	splines  reticulate the splines, then frobnicate the
+	         widgets, then do it all again, until
+	         everything is done.
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestHelpString(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout}
+	c.RegisterAll(c.HelpCmd(), &testCmd{})
+	if err := c.Run([]string{"subtest", "help", "help"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cout.Reset()
+
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+	test  a simple test
+`
+	if out := c.HelpString(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	out, err := c.CommandHelpString("test")
+	if err != flag.ErrHelp {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = `This is just a simple test.
+No, really. That's it.
+Probably.
+
+Options:
+  -flag string
+    	a flag test (default "test")
+`
+	if out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	_, err = c.CommandHelpString("missing")
+	if _, ok := err.(*sub.UnknownCommandError); !ok {
+		t.Errorf("Expected:\t%v", &sub.UnknownCommandError{Name: "missing"})
+		t.Errorf("Got:\t\t%v", err)
+	}
+
+	if cout.Len() != 0 {
+		t.Errorf("Output was written to: %q", cout.String())
+	}
+}
+
+func TestPrintHelp(t *testing.T) {
+	tests := []struct {
+		name  string
+		print func(*sub.Commander) error
+		args  []string
+	}{
+		{name: "Summary", print: (*sub.Commander).PrintHelp},
+		{name: "Command", print: func(c *sub.Commander) error { return c.PrintCommandHelp("test") }, args: []string{"test"}},
+		{name: "Missing", print: func(c *sub.Commander) error { return c.PrintCommandHelp("missing") }, args: []string{"missing"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got, want bytes.Buffer
+
+			c := &sub.Commander{Output: &want}
+			c.RegisterAll(c.HelpCmd(), &testCmd{})
+			wantErr := c.HelpCmd().Run(test.args)
+
+			c.Output = &got
+			err := test.print(c)
+			if err != wantErr {
+				t.Errorf("Expected:\t%v", wantErr)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("Expected:\t%q", want.String())
+				t.Errorf("Got:\t\t%q", got.String())
+			}
+		})
+	}
+}
+
+func TestLongHelp(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		cout string
+	}{
+		{
+			name: "Short",
+			args: []string{"subtest", "help"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Some help.
+
+Commands:
+	help  show help for commands
+`,
+		},
+		{
+			name: "Long",
+			args: []string{"subtest", "help", "-long"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Some help.
+
+A much longer description
+of the program.
+
+Commands:
+	help  show help for commands
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+
+			c := &sub.Commander{
+				Output:   &cout,
+				Help:     "Some help.",
+				LongHelp: "\nA much longer description\nof the program.\n",
+			}
+			c.Register(c.HelpCmd())
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
+
+func TestHeaderFooter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		footer string
+		cout   string
+	}{
+		{
+			name: "Empty",
+			cout: `Usage: subtest [global options] <subcommand> [subcommand arguments]
+
+Global Options:
+  -v	verbose output
+
+Commands:
+	help  show help for commands
+`,
+		},
+		{
+			name:   "Both",
+			header: "  Run 'subtest help <command>' for more information.\n",
+			footer: "\nFor bugs, visit the issue tracker.",
+			cout: `Usage: subtest [global options] <subcommand> [subcommand arguments]
+
+Global Options:
+  -v	verbose output
+
+Run 'subtest help <command>' for more information.
+
+Commands:
+	help  show help for commands
+
+For bugs, visit the issue tracker.
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, tmpl := range []string{"", sub.DefaultHelpTemplate} {
+				c := &sub.Commander{
+					Header:       test.header,
+					Footer:       test.footer,
+					HelpTemplate: tmpl,
+					Flags: func(fset *flag.FlagSet) {
+						fset.Bool("v", false, "verbose output")
+					},
+				}
+				c.SetName("subtest")
+				c.Register(c.HelpCmd())
+
+				if out := c.HelpString(); out != test.cout {
+					t.Errorf("Expected:\t%q", test.cout)
+					t.Errorf("Got:\t\t%q", out)
+				}
+			}
+		})
+	}
+}
+
+type seeAlsoCmd struct {
+	sub.Command
+	names []string
+}
+
+func (cmd seeAlsoCmd) SeeAlso() []string {
+	return cmd.names
+}
+
+func TestSeeAlso(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		color bool
+		cout  string
+	}{
+		{
+			name:  "Names",
+			names: []string{"help", "missing"},
+			cout:  "Does things.\n\nSee also: help, missing\n",
+		},
+		{
+			name: "None",
+			cout: "Does things.\n",
+		},
+		{
+			name:  "Links",
+			names: []string{"help", "https://example.com"},
+			color: true,
+			cout: "Does things.\n\nSee also: " +
+				"\x1b]8;;man:help\x1b\\help\x1b]8;;\x1b\\, " +
+				"\x1b]8;;https://example.com\x1b\\https://example.com\x1b]8;;\x1b\\\n",
+		},
+	}
+
+	restore := sub.SetIsTerminal(func(io.Writer) bool { return true })
+	defer restore()
+
+	for _, test := range tests {
+		var cout bytes.Buffer
+
+		c := &sub.Commander{Output: &cout, NoColor: !test.color}
+		c.RegisterAll(
+			c.HelpCmd(),
+			sub.WithGroup(seeAlsoCmd{
+				Command: sub.Func("thing", "do things", "Does things.", nil, nil),
+				names:   test.names,
+			}, "Things"),
+		)
+
+		err := c.Run([]string{"subtest", "help", "thing"})
+		if err != nil {
+			t.Fatalf("%v: Unexpected error: %v", test.name, err)
+		}
+		if out := cout.String(); out != test.cout {
+			t.Errorf("%v: Expected:\t%q", test.name, test.cout)
+			t.Errorf("%v: Got:\t\t%q", test.name, out)
+		}
+	}
+}
+
+func TestSilent(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		print bool
+		err   bool
+	}{
+		{name: "Unknown Global Flag", args: []string{"subtest", "-unknown"}, err: true},
+		{name: "No Args", args: []string{"subtest"}, err: true},
+		{name: "Missing Command", args: []string{"subtest", "missing"}, err: true},
+		{name: "Unknown Command Flag", args: []string{"subtest", "test", "-unknown"}, err: true},
+		{name: "Missing Required Flag", args: []string{"subtest", "login"}, err: true},
+		{name: "Deprecated", args: []string{"subtest", "old"}},
+		{name: "Global Help Flag", args: []string{"subtest", "-h"}, print: true, err: true},
+		{name: "Command Help Flag", args: []string{"subtest", "test", "-h"}, print: true, err: true},
+		{name: "Help Command", args: []string{"subtest", "help"}, print: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout, Silent: true}
+			c.RegisterAll(
+				c.HelpCmd(),
+				&testCmd{w: ioutil.Discard},
+				&requiredCmd{},
+				sub.Deprecated(sub.Func("old", "", "", nil, func([]string) error { return nil }), "don't"),
+			)
+
+			err := c.Run(test.args)
+			if (err != nil) != test.err {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if (cout.Len() != 0) != test.print {
+				t.Errorf("Output:\t%q", cout.String())
+			}
+		})
+	}
+}
+
+func TestDisableBuiltinHelp(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		check func(error) bool
+		print bool
+	}{
+		{
+			name:  "No Args",
+			args:  []string{"subtest"},
+			check: func(err error) bool { return err == sub.ErrNoCommand },
+		},
+		{
+			name: "Missing Command",
+			args: []string{"subtest", "missing"},
+			check: func(err error) bool {
+				var unknown *sub.UnknownCommandError
+				return errors.As(err, &unknown) && (unknown.Name == "missing")
+			},
+		},
+		{
+			name: "Unknown Command Flag",
+			args: []string{"subtest", "test", "-unknown"},
+			check: func(err error) bool {
+				var parse *sub.FlagParseError
+				return errors.As(err, &parse)
+			},
+		},
+		{
+			name:  "Global Help Flag",
+			args:  []string{"subtest", "-h"},
+			check: func(err error) bool { return err == flag.ErrHelp },
+		},
+		{
+			name:  "Help Command",
+			args:  []string{"subtest", "help"},
+			check: func(err error) bool { return err == nil },
+			print: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout, DisableBuiltinHelp: true}
+			c.RegisterAll(c.HelpCmd(), &testCmd{w: ioutil.Discard})
+
+			err := c.Run(test.args)
+			if !test.check(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if (cout.Len() != 0) != test.print {
+				t.Errorf("Output:\t%q", cout.String())
+			}
+		})
+	}
+}
+
+func TestUsageFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		cmdUsage bool
+		want     []string
+	}{
+		{name: "Bad Global Flag", args: []string{"subtest", "-unknown"}, want: []string{"usage"}},
+		{name: "No Args", args: []string{"subtest"}, want: []string{"usage"}},
+		{name: "Missing Command", args: []string{"subtest", "missing"}, want: []string{"usage"}},
+		{name: "Bad Command Flag", args: []string{"subtest", "test", "-unknown"}, want: []string{"usage"}},
+		{name: "Bad Command Flag Override", args: []string{"subtest", "test", "-unknown"}, cmdUsage: true, want: []string{"usage test"}},
+		{name: "Help Command", args: []string{"subtest", "help"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var got []string
+
+			c := &sub.Commander{
+				Output: &cout,
+				UsageFunc: func() {
+					got = append(got, "usage")
+				},
+			}
+			if test.cmdUsage {
+				c.CommandUsageFunc = func(cmd sub.Command) {
+					got = append(got, "usage "+cmd.Name())
+				}
+			}
+			c.RegisterAll(c.HelpCmd(), &testCmd{w: ioutil.Discard})
+
+			_ = c.Run(test.args)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected:\t%q", test.want)
+				t.Errorf("Got:\t\t%q", got)
+			}
+			if bytes.Contains(cout.Bytes(), []byte("Usage:")) != (test.want == nil) {
+				t.Errorf("Output:\t%q", cout.String())
+			}
+		})
+	}
+}
+
+func TestConcurrentRegister(t *testing.T) {
+	var c sub.Commander
+	c.Register(sub.Func("run", "", "", nil, func([]string) error { return nil }))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		i := i
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("cmd%v", i)
+			c.Register(sub.Func(name, "", "", nil, nil))
+			c.Unregister(name)
+		}()
+		go func() {
+			defer wg.Done()
+			c.Lookup("run")
+			c.Commands()
+			c.HelpString()
+		}()
+	}
+	wg.Wait()
+
+	if n := c.Count(); n != 1 {
+		t.Errorf("Expected:\t%v", 1)
+		t.Errorf("Got:\t\t%v", n)
+	}
+}
@@ -0,0 +1,67 @@
+// Package subtest provides helpers for testing programs that use sub.
+//
+// For example:
+//
+//    func TestGreet(t *testing.T) {
+//    	c, out := subtest.NewTestCommander(t)
+//    	c.Register(greetCmd{})
+//
+//    	subtest.AssertRun(t, c, []string{"greet", "world"}, nil)
+//    	subtest.AssertOutput(t, out, "Hello, world!\n")
+//    }
+package subtest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+// Name is the program name of the Commanders returned by
+// NewTestCommander. It is prepended to the arguments given to
+// AssertRun.
+const Name = "subtest"
+
+// NewTestCommander returns a new Commander named Name with its help
+// command registered, along with the buffer that its output is written
+// to.
+func NewTestCommander(t testing.TB) (*sub.Commander, *bytes.Buffer) {
+	t.Helper()
+
+	var out bytes.Buffer
+	c := sub.NewCommander(sub.WithName(Name), sub.WithOutput(&out))
+	c.Register(c.HelpCmd())
+	return c, &out
+}
+
+// AssertRun runs c with args, which should not include the program
+// name, and reports an error via t if the error returned by Run does
+// not match wantErr according to errors.Is. If wantErr is nil, Run is
+// expected to succeed.
+func AssertRun(t testing.TB, c *sub.Commander, args []string, wantErr error) {
+	t.Helper()
+
+	err := c.Run(append([]string{Name}, args...))
+	switch {
+	case (wantErr == nil) && (err != nil):
+		t.Errorf("Unexpected error running %q: %v", args, err)
+	case (wantErr != nil) && !errors.Is(err, wantErr):
+		t.Errorf("Running %q: Expected error:\t%v", args, wantErr)
+		t.Errorf("Running %q: Got:\t\t%v", args, err)
+	}
+}
+
+// AssertOutput reports an error via t if the contents of buf are not
+// want. buf is reset afterwards, so that consecutive calls only check
+// the output written in between them.
+func AssertOutput(t testing.TB, buf *bytes.Buffer, want string) {
+	t.Helper()
+
+	if got := buf.String(); got != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+	buf.Reset()
+}
@@ -0,0 +1,74 @@
+package subtest_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+	"github.com/DeedleFake/sub/subtest"
+)
+
+// recorder is a testing.TB that records errors instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNewTestCommander(t *testing.T) {
+	c, out := subtest.NewTestCommander(t)
+	if !c.Has("help") {
+		t.Errorf("Help command not registered")
+	}
+
+	c.Register(sub.Func("greet", "greet someone", "", nil, func(args []string) error {
+		fmt.Fprintf(out, "Hello, %v!\n", args[0])
+		return nil
+	}))
+
+	subtest.AssertRun(t, c, []string{"greet", "world"}, nil)
+	subtest.AssertOutput(t, out, "Hello, world!\n")
+	subtest.AssertRun(t, c, []string{"-h"}, flag.ErrHelp)
+	subtest.AssertOutput(t, out, c.HelpString())
+}
+
+func TestAssertions(t *testing.T) {
+	fail := errors.New("failed")
+
+	c, out := subtest.NewTestCommander(t)
+	c.Register(sub.Func("fail", "", "", nil, func([]string) error {
+		out.WriteString("failing\n")
+		return fail
+	}))
+
+	tests := []struct {
+		name   string
+		output string
+		assert func(t testing.TB)
+		errors int
+	}{
+		{name: "Run Expected Error", assert: func(t testing.TB) { subtest.AssertRun(t, c, []string{"fail"}, fail) }},
+		{name: "Run Unexpected Error", assert: func(t testing.TB) { subtest.AssertRun(t, c, []string{"fail"}, nil) }, errors: 1},
+		{name: "Run Wrong Error", assert: func(t testing.TB) { subtest.AssertRun(t, c, []string{"fail"}, flag.ErrHelp) }, errors: 2},
+		{name: "Output Match", output: "failing\n", assert: func(t testing.TB) { subtest.AssertOutput(t, out, "failing\n") }},
+		{name: "Output Mismatch", output: "failing\n", assert: func(t testing.TB) { subtest.AssertOutput(t, out, "passing\n") }, errors: 2},
+	}
+
+	for _, test := range tests {
+		out.Reset()
+		out.WriteString(test.output)
+
+		r := &recorder{TB: t}
+		test.assert(r)
+		if len(r.errors) != test.errors {
+			t.Errorf("%v: Expected %v errors, got %q", test.name, test.errors, r.errors)
+		}
+	}
+}
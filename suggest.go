@@ -0,0 +1,103 @@
+package sub
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions is the maximum number of names returned by Suggest.
+const maxSuggestions = 3
+
+// Suggest returns the names of up to three registered commands,
+// including aliases, that are closest to input, closest first. A name
+// is only considered if its edit distance from input is no more than
+// half of the number of characters in input. Hidden commands are never
+// suggested.
+func (c *Commander) Suggest(input string) []string {
+	type candidate struct {
+		name string
+		dist int
+	}
+
+	max := utf8.RuneCountInString(input) / 2
+	var candidates []candidate
+	for _, e := range c.entries() {
+		if isHidden(e.cmd) {
+			continue
+		}
+
+		dist := levenshtein(input, e.name)
+		if dist <= max {
+			candidates = append(candidates, candidate{name: e.name, dist: dist})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dist < candidates[j].dist
+	})
+	if len(candidates) > maxSuggestions {
+		candidates = candidates[:maxSuggestions]
+	}
+
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.name)
+	}
+	return names
+}
+
+// printNotFound prints the error message for a command that doesn't
+// exist, along with any suggestions for what the user may have meant,
+// followed by a blank line to separate it from the usage.
+func (c *Commander) printNotFound(w io.Writer, name string) {
+	c.printUnknown(w, name)
+	fmt.Fprintln(w)
+}
+
+// printUnknown prints the error message for a command that doesn't
+// exist, along with any suggestions for what the user may have meant.
+func (c *Commander) printUnknown(w io.Writer, name string) {
+	fmt.Fprintf(w, "%vNo such command: %q\n", c.errorPrefix(), name)
+	if suggestions := c.Suggest(name); len(suggestions) > 0 {
+		fmt.Fprintf(w, "Did you mean: %v?\n", strings.Join(suggestions, ", "))
+	}
+}
+
+// levenshtein returns the Levenshtein distance between a and b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := range ar {
+		cur[0] = i + 1
+		for j := range br {
+			cost := 1
+			if ar[i] == br[j] {
+				cost = 0
+			}
+
+			cur[j+1] = min3(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
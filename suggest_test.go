@@ -0,0 +1,77 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestSuggest(t *testing.T) {
+	var c sub.Commander
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.Func("status", "", "", nil, nil),
+		sub.Func("stats", "", "", nil, nil),
+		sub.Func("start", "", "", nil, nil),
+		sub.Func("stop", "", "", nil, nil),
+		sub.Func("abcd", "", "", nil, nil),
+		sub.Hidden(sub.Func("state", "", "", nil, nil)),
+	)
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Exact Match", input: "help", want: []string{"help"}},
+		{name: "Transposition", input: "hlep", want: []string{"help"}},
+		{name: "One Off", input: "statuss", want: []string{"status", "stats"}},
+		{name: "Limit", input: "statt", want: []string{"start", "stats", "status"}},
+		{name: "No Match", input: "frobnicate", want: []string{}},
+		{name: "Multi-Byte", input: "stöp", want: []string{"stop"}},
+		{name: "Multi-Byte Threshold", input: "éééé", want: []string{}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := c.Suggest(test.input)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected:\t%q", test.want)
+				t.Errorf("Got:\t\t%q", got)
+			}
+		})
+	}
+}
+
+func TestSuggestOutput(t *testing.T) {
+	var cout bytes.Buffer
+
+	c := &sub.Commander{Output: &cout}
+	c.Register(c.HelpCmd())
+
+	err := c.Run([]string{"subtest", "hlep"})
+	var unknown *sub.UnknownCommandError
+	if !errors.As(err, &unknown) {
+		t.Errorf("Expected:\t%v", &sub.UnknownCommandError{Name: "hlep"})
+		t.Errorf("Got:\t\t%v", err)
+	}
+
+	want := `Error: No such command: "hlep"
+Did you mean: help?
+
+Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
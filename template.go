@@ -0,0 +1,141 @@
+package sub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/DeedleFake/sub/internal/ansi"
+)
+
+// DefaultHelpTemplate is a help template that reproduces the built-in
+// help summary exactly. It can be used as a starting point for custom
+// templates.
+//
+// Templates are executed with a HelpTemplateData as their data. In addition
+// to the standard functions, they have access to two more:
+//
+//    underline   returns its argument underlined if styling is enabled
+//    commands    returns the command listing for a []CommandInfo
+const DefaultHelpTemplate = `{{.Usage}}
+{{with .Help}}
+{{.}}
+{{end}}{{with .LongHelp}}
+{{.}}
+{{end}}{{with .GlobalFlags}}
+{{underline "Global Options:"}}
+{{.}}{{end}}{{with .Header}}
+{{.}}
+{{end}}{{commands .Commands}}{{with .Footer}}
+{{.}}
+{{end}}`
+
+// HelpTemplateData is the data passed to a Commander's HelpTemplate.
+type HelpTemplateData struct {
+	// Name is the name of the program.
+	Name string
+
+	// Usage is the usage line of the program.
+	Usage string
+
+	// Help is the Commander's Help field with surrounding whitespace
+	// removed.
+	Help string
+
+	// LongHelp is the Commander's LongHelp field with surrounding
+	// whitespace removed if the help command was run with the -long
+	// flag. Otherwise, it is empty.
+	LongHelp string
+
+	// Header and Footer are the Commander's Header and Footer fields
+	// with surrounding whitespace removed.
+	Header string
+	Footer string
+
+	// Commands lists the commands to be shown, ordered by priority and
+	// then by the Commander's Sort field.
+	Commands []CommandInfo
+
+	// GlobalFlags is the description of the global flags, as printed by
+	// flag.FlagSet.PrintDefaults.
+	GlobalFlags string
+}
+
+// CommandInfo describes a single command for a HelpTemplate.
+type CommandInfo struct {
+	Name       string
+	Desc       string
+	Group      string
+	Hidden     bool
+	Deprecated string
+
+	cmd Command
+}
+
+// templateData returns the data for a help template.
+func (h *helpCmd) templateData() HelpTemplateData {
+	help := h.helpData(false)
+	data := HelpTemplateData{
+		Name:     help.Name,
+		Usage:    help.Usage,
+		Help:     help.Help,
+		LongHelp: help.LongHelp,
+		Header:   strings.TrimSpace(h.Header),
+		Footer:   strings.TrimSpace(h.Footer),
+	}
+	if h.hasGlobalFlags() {
+		data.GlobalFlags = h.globalDefaults()
+	}
+	for _, cmd := range help.Commands {
+		data.Commands = append(data.Commands, CommandInfo{
+			Name:       cmd.Name,
+			Desc:       cmd.Desc,
+			Group:      cmd.Group,
+			Hidden:     cmd.Hidden,
+			Deprecated: cmd.Deprecated,
+
+			cmd: cmd.cmd,
+		})
+	}
+	return data
+}
+
+// templateRenderer renders the help summary using a template. Command
+// help is rendered by the embedded textRenderer.
+type templateRenderer struct {
+	textRenderer
+	text string
+}
+
+func (r templateRenderer) RenderGlobalHelp(w io.Writer, c *Commander) {
+	h := r.h.clone(c)
+	h.out = w
+
+	tmpl, err := template.New("help").Funcs(template.FuncMap{
+		"underline": func(text string) string {
+			return h.style(ansi.Underline, text)
+		},
+		"commands": func(infos []CommandInfo) string {
+			cmds := make([]Command, 0, len(infos))
+			for _, info := range infos {
+				cmds = append(cmds, info.cmd)
+			}
+
+			var buf bytes.Buffer
+			h.printCommands(&buf, cmds)
+			return buf.String()
+		},
+	}).Parse(r.text)
+	if err != nil {
+		fmt.Fprintf(w, "Error: invalid help template: %v\n\n", err)
+		r.textRenderer.RenderGlobalHelp(w, c)
+		return
+	}
+
+	err = tmpl.Execute(w, h.templateData())
+	if err != nil {
+		fmt.Fprintf(w, "\nError: help template: %v\n", err)
+	}
+}
@@ -0,0 +1,95 @@
+package sub_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func newTemplateCommander(tmpl string) *sub.Commander {
+	c := sub.NewCommander(
+		sub.WithName("subtest"),
+		sub.WithHelp("Some help."),
+		sub.WithFlags(func(fset *flag.FlagSet) {
+			fset.Bool("v", false, "verbose output")
+		}),
+	)
+	c.HelpTemplate = tmpl
+	c.Register(c.HelpCmd())
+	c.Register(&testCmd{})
+	c.Register(&aliasedCmd{})
+	c.Register(sub.WithGroup(sub.Func("build", "build things", "", nil, nil), "Development"))
+	c.Register(sub.Deprecated(sub.Func("old", "an old command", "", nil, nil), "don't"))
+	c.Register(sub.Hidden(sub.Func("secret", "a hidden command", "", nil, nil)))
+	return c
+}
+
+func TestDefaultHelpTemplate(t *testing.T) {
+	want := newTemplateCommander("").HelpString()
+	got := newTemplateCommander(sub.DefaultHelpTemplate).HelpString()
+	if got != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+
+	for _, flag := range []string{"-all", "-long"} {
+		var wantOut, gotOut bytes.Buffer
+		c := newTemplateCommander("")
+		c.LongHelp = "Some more help."
+		c.Output = &wantOut
+		c.Run([]string{"subtest", "help", flag})
+		c = newTemplateCommander(sub.DefaultHelpTemplate)
+		c.LongHelp = "Some more help."
+		c.Output = &gotOut
+		c.Run([]string{"subtest", "help", flag})
+		if gotOut.String() != wantOut.String() {
+			t.Errorf("Expected:\t%q", wantOut.String())
+			t.Errorf("Got:\t\t%q", gotOut.String())
+		}
+		if (flag == "-long") && !strings.Contains(gotOut.String(), "Some more help.") {
+			t.Errorf("LongHelp not shown: %q", gotOut.String())
+		}
+	}
+}
+
+func TestHelpTemplate(t *testing.T) {
+	c := newTemplateCommander(`== {{.Name}} ==
+{{range .Commands}}{{.Name}}{{with .Group}} ({{.}}){{end}}{{if .Hidden}} hidden{{end}}{{with .Deprecated}} deprecated: {{.}}{{end}}
+{{end}}`)
+
+	want := `== subtest ==
+build (Development)
+help
+old deprecated: don't
+rm
+test
+`
+	if out := c.HelpString(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	cmdHelp, err := c.CommandHelpString("test")
+	if err != flag.ErrHelp {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cmdHelp, "This is just a simple test.") {
+		t.Errorf("Command help not rendered by default: %q", cmdHelp)
+	}
+}
+
+func TestHelpTemplateInvalid(t *testing.T) {
+	c := newTemplateCommander("{{.Name")
+	out := c.HelpString()
+
+	if !strings.HasPrefix(out, "Error: invalid help template: ") {
+		t.Errorf("Template error not reported: %q", out)
+	}
+	if want := newTemplateCommander("").HelpString(); !strings.HasSuffix(out, "\n\n"+want) {
+		t.Errorf("Expected suffix:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
@@ -0,0 +1,63 @@
+package sub
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// defaultWidth is the width of the output if it can't be
+	// determined any other way.
+	defaultWidth = 80
+
+	// minWrapWidth is the narrowest column that text will be wrapped
+	// to. If less space than that is available, text isn't wrapped.
+	minWrapWidth = 20
+
+	// tabWidth is the assumed width of a tab character.
+	tabWidth = 8
+)
+
+// width returns the width, in columns, that help output should be
+// wrapped to. It is MaxWidth if that is set, and otherwise the value
+// of the COLUMNS environment variable, if valid, or 80.
+func (c *Commander) width() int {
+	if c.MaxWidth > 0 {
+		return c.MaxWidth
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); (err == nil) && (columns > 0) {
+		return columns
+	}
+
+	return defaultWidth
+}
+
+// wrap splits text into lines no longer than width characters,
+// breaking only at whitespace. Words longer than width are placed on
+// their own line and are not broken. If width is less than
+// minWrapWidth, the text is returned as a single line.
+func wrap(text string, width int) []string {
+	if width < minWrapWidth {
+		return []string{text}
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
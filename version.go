@@ -0,0 +1,95 @@
+package sub
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrVersion is returned by Run when the -version global flag is
+// given. The version is printed before the command is looked up, so
+// no command is run, even if one is given after the flag.
+var ErrVersion = errors.New("version requested")
+
+// versionFlag is the name of the global flag that prints the version.
+const versionFlag = "version"
+
+// SetVersion sets the version of the program. Once the version is
+// set, the Commander has a -version global flag that prints the
+// version instead of running a command, in which case Run returns
+// ErrVersion. SetVersion also registers the command returned by
+// VersionCmd.
+//
+// The global flags must not include their own flag named version.
+func (c *Commander) SetVersion(version string) {
+	c.version = version
+	c.Register(c.VersionCmd())
+}
+
+// Version returns the version set by SetVersion, or AutoVersion if
+// SetVersion hasn't been called.
+func (c *Commander) Version() string {
+	if c.version == "" {
+		return c.AutoVersion
+	}
+	return c.version
+}
+
+func (c *Commander) printVersion(w io.Writer) {
+	fmt.Fprintf(w, "%v version %v\n", c.progName(), c.Version())
+}
+
+// versionRequested returns true if the -version flag was set in fset,
+// which should already have been parsed.
+func (c *Commander) versionRequested(fset *flag.FlagSet) bool {
+	if c.Version() == "" {
+		return false
+	}
+
+	f := fset.Lookup(versionFlag)
+	if f == nil {
+		return false
+	}
+	v, _ := strconv.ParseBool(f.Value.String())
+	return v
+}
+
+type versionCmd struct {
+	c *Commander
+}
+
+// VersionCmd returns a "version" Command that prints the version
+// returned by Version. It is registered automatically by SetVersion, but can
+// also be registered manually.
+func (c *Commander) VersionCmd() Command {
+	return &versionCmd{c: c}
+}
+
+func (cmd *versionCmd) Name() string {
+	return "version"
+}
+
+func (cmd *versionCmd) Desc() string {
+	return "show the version"
+}
+
+func (cmd *versionCmd) Help() string {
+	return `Usage: version
+
+version prints the version of the program.`
+}
+
+func (cmd *versionCmd) Flags(fset *flag.FlagSet) {
+}
+
+// ValidateArgs exempts version from MinArgs and MaxArgs.
+func (cmd *versionCmd) ValidateArgs(args []string) error {
+	return nil
+}
+
+func (cmd *versionCmd) Run(args []string) error {
+	cmd.c.printVersion(cmd.c.output())
+	return nil
+}
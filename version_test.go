@@ -0,0 +1,125 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		ret  error
+	}{
+		{name: "Flag", args: []string{"subtest", "-version"}, ret: sub.ErrVersion},
+		{name: "Flag Before Command", args: []string{"subtest", "-version", "test", "arg"}, ret: sub.ErrVersion},
+		{name: "Command", args: []string{"subtest", "version"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var testout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout}
+			c.Register(&testCmd{w: &testout})
+			c.SetVersion("1.2.3")
+
+			if v := c.Version(); v != "1.2.3" {
+				t.Errorf("Expected:\t%q", "1.2.3")
+				t.Errorf("Got:\t\t%q", v)
+			}
+
+			err := c.Run(test.args)
+			if err != test.ret {
+				t.Errorf("Expected:\t%v", test.ret)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if want := "subtest version 1.2.3\n"; cout.String() != want {
+				t.Errorf("Expected:\t%q", want)
+				t.Errorf("Got:\t\t%q", cout.String())
+			}
+			if testout.Len() != 0 {
+				t.Errorf("Command ran: %q", testout.String())
+			}
+		})
+	}
+}
+
+func TestAutoVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		ret  error
+		out  string
+	}{
+		{name: "Flag", args: []string{"subtest", "--version"}, ret: sub.ErrVersion, out: "subtest version 2.0.0\n"},
+		{name: "Flag Before Command", args: []string{"subtest", "--version", "test", "arg"}, ret: sub.ErrVersion, out: "subtest version 2.0.0\n"},
+		{name: "No Version Command", args: []string{"subtest", "version"}, ret: &sub.UnknownCommandError{Name: "version"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var testout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout, Silent: true, AutoVersion: "2.0.0"}
+			c.Register(&testCmd{w: &testout})
+
+			err := c.Run(test.args)
+			if !errors.Is(err, test.ret) && !reflect.DeepEqual(err, test.ret) {
+				t.Errorf("Expected:\t%v", test.ret)
+				t.Errorf("Got:\t\t%v", err)
+			}
+			if cout.String() != test.out {
+				t.Errorf("Expected:\t%q", test.out)
+				t.Errorf("Got:\t\t%q", cout.String())
+			}
+			if testout.Len() != 0 {
+				t.Errorf("Command ran: %q", testout.String())
+			}
+		})
+	}
+
+	c := &sub.Commander{AutoVersion: "2.0.0"}
+	if v := c.Version(); v != "2.0.0" {
+		t.Errorf("Expected:\t%q", "2.0.0")
+		t.Errorf("Got:\t\t%q", v)
+	}
+	c.SetVersion("3.0.0")
+	if v := c.Version(); v != "3.0.0" {
+		t.Errorf("Expected:\t%q", "3.0.0")
+		t.Errorf("Got:\t\t%q", v)
+	}
+}
+
+func TestVersionHelp(t *testing.T) {
+	c := sub.NewCommander(sub.WithName("subtest"))
+	c.Register(c.HelpCmd())
+	c.SetVersion("1.2.3")
+
+	want := `Usage: subtest [global options] <subcommand> [subcommand arguments]
+
+Global Options:
+  -version
+    	print the version and exit
+
+Commands:
+	help     show help for commands
+	version  show the version
+`
+	if out := c.HelpString(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
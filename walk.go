@@ -0,0 +1,45 @@
+package sub
+
+// ForEach calls fn for each command registered with c, in name order,
+// until fn returns false. Each command is visited once, regardless of
+// how many aliases it has. Commands belonging to nested Commanders are
+// not visited.
+//
+// Unlike Commands, ForEach doesn't copy the set of commands. Instead,
+// it holds c's read lock while iterating, so it is safe to call
+// concurrently with other methods that read c, but fn must not call
+// Register or Unregister on c.
+func (c *Commander) ForEach(fn func(cmd Command) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, e := range c.commands {
+		if !c.canonical(e) {
+			continue
+		}
+		if !fn(e.cmd) {
+			return
+		}
+	}
+}
+
+// Walk calls fn for each command registered with c, in name order, and
+// recursively for the commands of any nested Commanders registered via
+// AsCommand. depth is 0 for commands registered directly with c and
+// increases by one for each level of nesting. A nested Commander's own
+// command is visited before its children.
+//
+// fn must not call Register or Unregister on c or on any of the nested
+// Commanders.
+func (c *Commander) Walk(fn func(cmd Command, depth int)) {
+	c.walk(fn, 0)
+}
+
+func (c *Commander) walk(fn func(Command, int), depth int) {
+	for _, cmd := range c.Commands() {
+		fn(cmd, depth)
+		if nested := nestedOf(cmd); nested != nil {
+			nested.Commander.walk(fn, depth+1)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sub_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/DeedleFake/sub"
+)
+
+func newWalkCommander() *sub.Commander {
+	var inner sub.Commander
+	inner.Register(sub.Func("get", "get a value", "", nil, nil))
+	inner.Register(sub.Func("set", "set a value", "", nil, nil))
+
+	var c sub.Commander
+	c.Register(&aliasedCmd{})
+	c.Register(inner.AsCommand("config", "manage configuration"))
+	c.Register(sub.Func("run", "run things", "", nil, nil))
+	return &c
+}
+
+func TestWalk(t *testing.T) {
+	c := newWalkCommander()
+
+	var got []string
+	c.Walk(func(cmd sub.Command, depth int) {
+		got = append(got, fmt.Sprintf("%v:%v", depth, cmd.Name()))
+	})
+
+	want := []string{"0:config", "1:get", "1:set", "0:rm", "0:run"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	c := newWalkCommander()
+
+	var got []string
+	c.ForEach(func(cmd sub.Command) bool {
+		got = append(got, cmd.Name())
+		return true
+	})
+
+	want := []string{"config", "rm", "run"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+
+	got = nil
+	c.ForEach(func(cmd sub.Command) bool {
+		got = append(got, cmd.Name())
+		return len(got) < 2
+	})
+
+	want = []string{"config", "rm"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", got)
+	}
+}
@@ -0,0 +1,330 @@
+package sub
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// wrapper is embedded by the Command wrappers in this package. It
+// forwards the methods of the package's optional interfaces to the
+// wrapped Command, falling back to the default behavior if the
+// wrapped Command doesn't implement them, so that wrapping a command
+// doesn't hide any of its functionality.
+type wrapper struct {
+	Command
+}
+
+// inner returns the wrapped Command. Along with the rewrap method of
+// each of the wrappers that embed wrapper, which returns a copy of
+// the wrapper around a different Command, it lets Clone replace
+// commands that are tied to a Commander even when they are wrapped.
+func (w wrapper) inner() Command {
+	return w.Command
+}
+
+func (w wrapper) Aliases() []string {
+	if cmd, ok := w.Command.(AliasedCommand); ok {
+		return cmd.Aliases()
+	}
+	return nil
+}
+
+func (w wrapper) Hidden() bool {
+	return isHidden(w.Command)
+}
+
+func (w wrapper) Deprecated() string {
+	return deprecation(w.Command)
+}
+
+func (w wrapper) Example() string {
+	if cmd, ok := w.Command.(ExampleProvider); ok {
+		return cmd.Example()
+	}
+	return ""
+}
+
+func (w wrapper) SeeAlso() []string {
+	return seeAlsoOf(w.Command)
+}
+
+func (w wrapper) Group() string {
+	return groupOf(w.Command)
+}
+
+func (w wrapper) Priority() int {
+	return priorityOf(w.Command)
+}
+
+func (w wrapper) PreRun(args []string) error {
+	if cmd, ok := w.Command.(PreRunner); ok {
+		return cmd.PreRun(args)
+	}
+	return nil
+}
+
+func (w wrapper) PostRun(args []string, err error) error {
+	if cmd, ok := w.Command.(PostRunner); ok {
+		return cmd.PostRun(args, err)
+	}
+	return err
+}
+
+func (w wrapper) RequiredFlags() []string {
+	if cmd, ok := w.Command.(RequiredFlagsCommand); ok {
+		return cmd.RequiredFlags()
+	}
+	return nil
+}
+
+func (w wrapper) SetInput(r io.Reader) {
+	if cmd, ok := w.Command.(InputCommand); ok {
+		cmd.SetInput(r)
+	}
+}
+
+func (w wrapper) SetIO(io IO) {
+	if cmd, ok := w.Command.(IOCommand); ok {
+		cmd.SetIO(io)
+	}
+}
+
+func (w wrapper) ioCommand() IOCommand {
+	return ioCommandOf(w.Command)
+}
+
+// dryRunner is unexported so that wrapped commands don't all appear to
+// implement DryRunner.
+func (w wrapper) dryRunner() DryRunner {
+	return dryRunnerOf(w.Command)
+}
+
+// argsValidator is unexported for the same reason as dryRunner, as
+// any ArgsValidator replaces the Commander's argument limits.
+func (w wrapper) argsValidator() ArgsValidator {
+	return argsValidatorOf(w.Command)
+}
+
+// completionProvider is unexported so that wrapped commands don't all
+// appear to implement CompletionProvider.
+func (w wrapper) completionProvider() CompletionProvider {
+	return completionProviderOf(w.Command)
+}
+
+// nested and plugin let the Commander see the commands that need
+// special handling while parsing through the wrappers.
+func (w wrapper) nested() *commanderCmd {
+	return nestedOf(w.Command)
+}
+
+func (w wrapper) plugin() *PluginCommand {
+	return pluginOf(w.Command)
+}
+
+func (w wrapper) RunContext(ctx context.Context, args []string) error {
+	return runCommand(ctx, w.Command, args)
+}
+
+// HiddenCommand is a Command that can be hidden from the help
+// listing. Hidden commands can still be run normally.
+type HiddenCommand interface {
+	Command
+
+	// Hidden returns true if the command should be hidden.
+	Hidden() bool
+}
+
+func isHidden(cmd Command) bool {
+	hidden, ok := cmd.(HiddenCommand)
+	return ok && hidden.Hidden()
+}
+
+type hiddenCmd struct {
+	wrapper
+}
+
+// Hidden returns a Command that behaves identically to cmd but is
+// hidden from the help listing unless the help command is run with
+// the -all flag.
+func Hidden(cmd Command) Command {
+	return hiddenCmd{wrapper{cmd}}
+}
+
+func (cmd hiddenCmd) Hidden() bool {
+	return true
+}
+
+func (cmd hiddenCmd) rewrap(inner Command) Command {
+	cmd.Command = inner
+	return cmd
+}
+
+// DeprecatedCommand is a Command that can be marked as deprecated.
+// When a deprecated command is run, a warning is printed before the
+// command itself is run, and the help listing marks it as
+// deprecated.
+type DeprecatedCommand interface {
+	Command
+
+	// Deprecated returns a message explaining why the command is
+	// deprecated and what, if anything, should be used instead. If it
+	// returns an empty string, the command is not deprecated.
+	Deprecated() string
+}
+
+func deprecation(cmd Command) string {
+	if deprecated, ok := cmd.(DeprecatedCommand); ok {
+		return deprecated.Deprecated()
+	}
+	return ""
+}
+
+type deprecatedCmd struct {
+	wrapper
+	message string
+}
+
+// Deprecated returns a Command that behaves identically to cmd but is
+// marked as deprecated with the given message.
+func Deprecated(cmd Command, message string) Command {
+	return deprecatedCmd{
+		wrapper: wrapper{cmd},
+		message: message,
+	}
+}
+
+// ReplacedBy is like Deprecated, but generates a message pointing
+// users towards the command named replacement.
+func ReplacedBy(cmd Command, replacement string) Command {
+	return Deprecated(cmd, fmt.Sprintf("use %q instead", replacement))
+}
+
+func (cmd deprecatedCmd) Deprecated() string {
+	return cmd.message
+}
+
+func (cmd deprecatedCmd) rewrap(inner Command) Command {
+	cmd.Command = inner
+	return cmd
+}
+
+// GroupedCommand is a Command that belongs to a group. The help
+// listing shows each group of commands in its own section.
+type GroupedCommand interface {
+	Command
+
+	// Group returns the name of the command's group. If it returns an
+	// empty string, the command is listed with commands that don't
+	// belong to any group.
+	Group() string
+}
+
+func groupOf(cmd Command) string {
+	if grouped, ok := cmd.(GroupedCommand); ok {
+		return grouped.Group()
+	}
+	return ""
+}
+
+type groupCmd struct {
+	wrapper
+	group string
+}
+
+// WithGroup returns a Command that behaves identically to cmd but
+// belongs to the given group.
+func WithGroup(cmd Command, group string) Command {
+	return groupCmd{
+		wrapper: wrapper{cmd},
+		group:   group,
+	}
+}
+
+func (cmd groupCmd) Group() string {
+	return cmd.group
+}
+
+func (cmd groupCmd) rewrap(inner Command) Command {
+	cmd.Command = inner
+	return cmd
+}
+
+// PrioritizedCommand is a Command with a priority that determines
+// where it is listed in the help summary. Commands are listed in
+// ascending order of priority, and commands with the same priority are
+// ordered according to the Commander's Sort field. Commands that don't
+// implement PrioritizedCommand have a priority of 0, so a negative
+// priority moves a command above them and a positive one moves it
+// below them.
+type PrioritizedCommand interface {
+	Command
+
+	// Priority returns the command's priority.
+	Priority() int
+}
+
+func priorityOf(cmd Command) int {
+	if prioritized, ok := cmd.(PrioritizedCommand); ok {
+		return prioritized.Priority()
+	}
+	return 0
+}
+
+type priorityCmd struct {
+	wrapper
+	priority int
+}
+
+// WithPriority returns a Command that behaves identically to cmd but
+// has the given priority.
+func WithPriority(cmd Command, priority int) Command {
+	return priorityCmd{
+		wrapper:  wrapper{cmd},
+		priority: priority,
+	}
+}
+
+func (cmd priorityCmd) Priority() int {
+	return cmd.priority
+}
+
+func (cmd priorityCmd) rewrap(inner Command) Command {
+	cmd.Command = inner
+	return cmd
+}
+
+type timedCmd struct {
+	wrapper
+	report func(name string, d time.Duration)
+}
+
+// Timed returns a Command that behaves identically to cmd but measures
+// how long it takes to run. After it runs, whether or not it fails,
+// report is called with the command's name and the duration. Unlike
+// TimingMiddleware, it applies only to cmd.
+func Timed(cmd Command, report func(name string, d time.Duration)) Command {
+	return timedCmd{
+		wrapper: wrapper{cmd},
+		report:  report,
+	}
+}
+
+func (cmd timedCmd) Run(args []string) error {
+	return cmd.RunContext(context.Background(), args)
+}
+
+func (cmd timedCmd) rewrap(inner Command) Command {
+	cmd.Command = inner
+	return cmd
+}
+
+func (cmd timedCmd) RunContext(ctx context.Context, args []string) error {
+	start := time.Now()
+	defer func() {
+		cmd.report(cmd.Name(), time.Since(start))
+	}()
+
+	return cmd.wrapper.RunContext(ctx, args)
+}
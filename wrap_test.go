@@ -0,0 +1,319 @@
+package sub_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeedleFake/sub"
+)
+
+func TestHidden(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		cout    string
+		testout string
+	}{
+		{
+			name: "Listing",
+			args: []string{"subtest", "help"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+`,
+		},
+		{
+			name: "Listing All",
+			args: []string{"subtest", "help", "-all"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+	test  a simple test [hidden]
+`,
+		},
+		{
+			name:    "Run",
+			args:    []string{"subtest", "test", "arg"},
+			testout: `"arg"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var testout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout}
+			c.Register(c.HelpCmd())
+			c.Register(sub.Hidden(&testCmd{w: &testout}))
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+
+			if out := testout.String(); out != test.testout {
+				t.Errorf("Expected:\t%q", test.testout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		cout    string
+		testout string
+	}{
+		{
+			name: "Listing",
+			args: []string{"subtest", "help"},
+			cout: `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help  show help for commands
+	old   a simple test [deprecated]
+	test  a simple test [deprecated]
+`,
+		},
+		{
+			name: "Command Help",
+			args: []string{"subtest", "help", "old"},
+			cout: `Deprecated: use "new" instead
+
+This is just a simple test.
+No, really. That's it.
+Probably.
+
+Options:
+  -flag string
+    	a flag test (default "test")
+`,
+		},
+		{
+			name:    "Run",
+			args:    []string{"subtest", "test", "arg"},
+			cout:    "Warning: command \"test\" is deprecated: no longer needed\n",
+			testout: `"arg"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cout bytes.Buffer
+			var testout bytes.Buffer
+
+			c := &sub.Commander{Output: &cout}
+			c.Register(c.HelpCmd())
+			c.Register(sub.Deprecated(&testCmd{w: &testout}, "no longer needed"))
+			c.Register(sub.ReplacedBy(&renamedCmd{testCmd{w: &testout}}, "new"))
+
+			err := c.Run(test.args)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if out := cout.String(); out != test.cout {
+				t.Errorf("Expected:\t%q", test.cout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+
+			if out := testout.String(); out != test.testout {
+				t.Errorf("Expected:\t%q", test.testout)
+				t.Errorf("Got:\t\t%q", out)
+			}
+		})
+	}
+}
+
+type renamedCmd struct {
+	testCmd
+}
+
+func (cmd *renamedCmd) Name() string {
+	return "old"
+}
+
+func TestRegisterGroup(t *testing.T) {
+	var cout bytes.Buffer
+	c := &sub.Commander{Output: &cout}
+	c.Register(c.HelpCmd())
+	c.RegisterGroup("Resource",
+		sub.Func("create", "create a resource", "", nil, nil),
+		sub.WithGroup(sub.Func("delete", "delete a resource", "", nil, nil), "Account"),
+	)
+	c.RegisterGroup("Account", sub.Func("login", "log in", "", nil, nil))
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help    show help for commands
+
+Account commands:
+	login   log in
+
+Resource commands:
+	create  create a resource
+	delete  delete a resource
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+}
+
+func TestRegisterGroupNested(t *testing.T) {
+	var cout, cerr bytes.Buffer
+	var ran []string
+
+	var inner sub.Commander
+	inner.Register(sub.Func("add", "add a remote", "", nil, func(args []string) error {
+		ran = args
+		return nil
+	}))
+
+	c := &sub.Commander{IO: sub.IO{Out: &cout, Err: &cerr}, Interspersed: true}
+	c.Register(c.HelpCmd())
+	c.RegisterGroup("Remote", inner.AsCommand("remote", "manage remotes"))
+
+	err := c.Run([]string{"subtest", "remote", "-h"})
+	if err != flag.ErrHelp {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "Usage: subtest remote <subcommand>"; !strings.HasPrefix(cerr.String()+cout.String(), want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", cerr.String()+cout.String())
+	}
+
+	err = c.Run([]string{"subtest", "remote", "add", "origin"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"origin"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", ran)
+	}
+
+	var visited []string
+	c.Walk(func(cmd sub.Command, depth int) {
+		visited = append(visited, cmd.Name())
+	})
+	if want := []string{"help", "remote", "add"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", visited)
+	}
+}
+
+func TestWithGroup(t *testing.T) {
+	var cout bytes.Buffer
+	var ran bool
+
+	run := func([]string) error {
+		ran = true
+		return nil
+	}
+
+	c := &sub.Commander{Output: &cout}
+	c.RegisterAll(
+		c.HelpCmd(),
+		sub.WithGroup(sub.Func("create", "create a resource", "", nil, run), "Resource"),
+		sub.WithGroup(sub.Func("delete", "delete a resource", "", nil, nil), "Resource"),
+		sub.WithGroup(sub.Func("login", "log in", "", nil, nil), "Account"),
+	)
+
+	err := c.Run([]string{"subtest", "help"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `Usage: subtest <subcommand> [subcommand arguments]
+
+Commands:
+	help    show help for commands
+
+Account commands:
+	login   log in
+
+Resource commands:
+	create  create a resource
+	delete  delete a resource
+`
+	if out := cout.String(); out != want {
+		t.Errorf("Expected:\t%q", want)
+		t.Errorf("Got:\t\t%q", out)
+	}
+
+	err = c.Run([]string{"subtest", "create"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ran {
+		t.Errorf("Grouped command did not run")
+	}
+}
+
+func TestTimed(t *testing.T) {
+	type report struct {
+		name string
+		d    time.Duration
+	}
+	var reports []report
+
+	fail := errors.New("failed")
+	cmd := sub.Timed(
+		sub.WithGroup(sub.Func("sleep", "sleep for a bit", "", nil, func([]string) error {
+			time.Sleep(time.Millisecond)
+			return fail
+		}), "Time"),
+		func(name string, d time.Duration) {
+			reports = append(reports, report{name: name, d: d})
+		},
+	)
+
+	c := &sub.Commander{Output: new(bytes.Buffer)}
+	c.Register(cmd)
+
+	err := c.Run([]string{"subtest", "sleep"})
+	if err != fail {
+		t.Errorf("Expected:\t%v", fail)
+		t.Errorf("Got:\t\t%v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report, got %v", len(reports))
+	}
+	if reports[0].name != "sleep" {
+		t.Errorf("Expected:\t%q", "sleep")
+		t.Errorf("Got:\t\t%q", reports[0].name)
+	}
+	if reports[0].d < time.Millisecond {
+		t.Errorf("Duration too short: %v", reports[0].d)
+	}
+
+	grouped, ok := cmd.(sub.GroupedCommand)
+	if !ok || (grouped.Group() != "Time") {
+		t.Errorf("Group was not forwarded")
+	}
+}